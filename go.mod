@@ -5,5 +5,7 @@ go 1.11
 require (
 	cloud.google.com/go v0.60.0
 	cloud.google.com/go/logging v1.0.0
+	google.golang.org/api v0.28.0
 	google.golang.org/genproto v0.0.0-20200707001353-8e8330bf89df
+	google.golang.org/grpc v1.29.1
 )
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+	"sync"
+)
+
+type attachKey struct{}
+
+type attached struct {
+	mtx    sync.Mutex
+	fields map[string]interface{}
+}
+
+// Attach adds a diagnostic field to a Context created by ForRequest,
+// ForEvent or ForContext, to be added only to entries at Error or above
+// logged with it, keeping other entries lean.
+// It's safe to call concurrently; on other Contexts it does nothing.
+//
+//	logging.Attach(ctx, "flags", flags)
+func Attach(ctx context.Context, key string, value interface{}) {
+	if ctx == nil {
+		return
+	}
+	a, _ := ctx.Value(attachKey{}).(*attached)
+	if a == nil {
+		return
+	}
+	a.mtx.Lock()
+	if a.fields == nil {
+		a.fields = map[string]interface{}{}
+	}
+	a.fields[key] = normalize(value)
+	a.mtx.Unlock()
+}
+
+func (a *attached) get() map[string]interface{} {
+	if a == nil {
+		return nil
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return addFields(a.fields, nil)
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+// An AuditEvent is an action recorded by Logger.Audit.
+type AuditEvent struct {
+	// Principal is the email of the authenticated principal (required).
+	Principal string
+	// MethodName is the name of the operation (required; example: "users.delete").
+	MethodName string
+	// ResourceName is the resource the operation acted on (required).
+	ResourceName string
+	// ServiceName is the name of the service performing the operation.
+	ServiceName string
+	// Request and Metadata are optional additional details.
+	Request  map[string]interface{}
+	Metadata map[string]interface{}
+	// Severity of the entry (default: Notice).
+	Severity logging.Severity
+}
+
+var auditLogger atomic.Value
+
+// SetAuditLogName sets the log ID audit events are written to (default: "audit").
+func SetAuditLogName(logID string) {
+	auditLogger.Store(NewNamedLogger(logID))
+}
+
+func getAuditLogger() *NamedLogger {
+	if nl, ok := auditLogger.Load().(*NamedLogger); ok {
+		return nl
+	}
+	namedMtx.Lock()
+	defer namedMtx.Unlock()
+	if nl, ok := auditLogger.Load().(*NamedLogger); ok {
+		return nl
+	}
+	nl := &NamedLogger{id: "audit"}
+	named = append(named, nl)
+	auditLogger.Store(nl)
+	return nl
+}
+
+// Audit logs the event to the audit log, see SetAuditLogName,
+// with a payload in the shape of a Cloud Audit Log
+// (type.googleapis.com/google.cloud.audit.AuditLog).
+// The Logger's severity is ignored, in favor of that of the event.
+//
+// It returns an error, without logging, if required fields are missing.
+func (l Logger) Audit(event AuditEvent) error {
+	switch {
+	case event.Principal == "":
+		return errors.New("logging: audit event missing Principal")
+	case event.MethodName == "":
+		return errors.New("logging: audit event missing MethodName")
+	case event.ResourceName == "":
+		return errors.New("logging: audit event missing ResourceName")
+	}
+	if l.noop {
+		return nil
+	}
+
+	fields := map[string]interface{}{
+		"@type":              "type.googleapis.com/google.cloud.audit.AuditLog",
+		"authenticationInfo": map[string]interface{}{"principalEmail": event.Principal},
+		"methodName":         event.MethodName,
+		"resourceName":       event.ResourceName,
+	}
+	if event.ServiceName != "" {
+		fields["serviceName"] = event.ServiceName
+	}
+	if event.Request != nil {
+		fields["request"] = event.Request
+	}
+	if event.Metadata != nil {
+		fields["metadata"] = event.Metadata
+	}
+
+	l.s = event.Severity
+	if l.s == logging.Default {
+		l.s = logging.Notice
+	}
+	l.nl = getAuditLogger()
+	l.withBuiltins(fields).log(event.MethodName)
+	return nil
+}
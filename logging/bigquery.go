@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+// An OutputFormat is the format of JSON output; see SetOutputFormat.
+type OutputFormat int32
+
+const (
+	// AgentFormat is the format recognized by the Cloud Logging agent.
+	AgentFormat OutputFormat = iota
+	// BigQueryFormat is a flat record, matching BigQuerySchema.
+	BigQueryFormat
+)
+
+// BigQuerySchema is the BigQuery table schema of BigQueryFormat records,
+// in the JSON format accepted by the bq tool.
+// The fields column holds structured fields, as a JSON object.
+const BigQuerySchema = `[
+	{"name": "timestamp", "type": "TIMESTAMP", "mode": "REQUIRED"},
+	{"name": "severity", "type": "STRING", "mode": "REQUIRED"},
+	{"name": "message", "type": "STRING", "mode": "NULLABLE"},
+	{"name": "trace", "type": "STRING", "mode": "NULLABLE"},
+	{"name": "span_id", "type": "STRING", "mode": "NULLABLE"},
+	{"name": "execution_id", "type": "STRING", "mode": "NULLABLE"},
+	{"name": "labels", "type": "RECORD", "mode": "REPEATED", "fields": [
+		{"name": "key", "type": "STRING", "mode": "REQUIRED"},
+		{"name": "value", "type": "STRING", "mode": "NULLABLE"}
+	]},
+	{"name": "fields", "type": "STRING", "mode": "NULLABLE"}
+]`
+
+var outputFormat int32
+
+// SetOutputFormat sets the format of JSON output; see SetOutput
+// (default: AgentFormat).
+func SetOutputFormat(f OutputFormat) {
+	atomic.StoreInt32(&outputFormat, int32(f))
+}
+
+type bigQueryLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func bigQueryRecord(entry logging.Entry, msg string, fields map[string]interface{}) map[string]interface{} {
+	t := entry.Timestamp
+	if t.IsZero() {
+		t = now()
+	}
+
+	labels := make([]bigQueryLabel, 0, len(entry.Labels))
+	for k, v := range entry.Labels {
+		labels = append(labels, bigQueryLabel{k, v})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Key < labels[j].Key })
+
+	m := map[string]interface{}{
+		"timestamp": t.UTC().Format(time.RFC3339Nano),
+		"severity":  strings.ToUpper(entry.Severity.String()),
+		"message":   msg,
+		"labels":    labels,
+	}
+	if entry.Trace != "" {
+		m["trace"] = entry.Trace
+	}
+	if entry.SpanID != "" {
+		m["span_id"] = entry.SpanID
+	}
+	if id := entry.Labels[executionIDLabel()]; id != "" {
+		m["execution_id"] = id
+	}
+	if len(fields) > 0 {
+		if buf, err := json.Marshal(fields); err == nil {
+			m["fields"] = string(buf)
+		}
+	}
+	return m
+}
@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A BreakerState is the state of the circuit breaker; see SetCircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed sends entries to Cloud Logging.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen logs entries locally, as if there was no logging client.
+	BreakerOpen
+	// BreakerHalfOpen sends entries to Cloud Logging, to test recovery.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+var (
+	breakerMtx      sync.Mutex
+	breakerLimit    int
+	breakerCooldown time.Duration
+	breakerState    BreakerState
+	breakerFailures int
+	breakerLast     time.Time
+	breakerUntil    time.Time
+	breakerEnabled  int32
+)
+
+// SetCircuitBreaker opens a circuit after failures consecutive errors
+// sending entries to Cloud Logging, logging locally for the cooldown,
+// as if there was no logging client (see SetFallback).
+// After the cooldown, the circuit is half-open: entries are sent again,
+// and the circuit closes if there are no errors for another cooldown,
+// or opens on the first error.
+//
+// Errors are consecutive if less than the cooldown apart.
+// Zero failures disables the circuit breaker (the default).
+func SetCircuitBreaker(failures int, cooldown time.Duration) {
+	var v int32
+	if failures > 0 {
+		v = 1
+	}
+	breakerMtx.Lock()
+	breakerLimit, breakerCooldown = failures, cooldown
+	breakerState, breakerFailures = BreakerClosed, 0
+	atomic.StoreInt32(&breakerEnabled, v)
+	breakerMtx.Unlock()
+}
+
+func breakerAllow() bool {
+	// Avoid the lock, when disabled.
+	if atomic.LoadInt32(&breakerEnabled) == 0 {
+		return true
+	}
+	breakerMtx.Lock()
+	defer breakerMtx.Unlock()
+	if breakerLimit <= 0 {
+		return true
+	}
+
+	switch breakerState {
+	case BreakerOpen:
+		if now().Before(breakerUntil) {
+			return false
+		}
+		breakerState, breakerUntil = BreakerHalfOpen, now().Add(breakerCooldown)
+	case BreakerHalfOpen:
+		if !now().Before(breakerUntil) {
+			breakerState, breakerFailures = BreakerClosed, 0
+		}
+	}
+	return true
+}
+
+// onClientError is the OnError function of logging clients.
+func onClientError(err error) {
+	log.Printf("logging client: %v", err)
+	if atomic.LoadInt32(&breakerEnabled) == 0 {
+		return
+	}
+
+	breakerMtx.Lock()
+	defer breakerMtx.Unlock()
+	if breakerLimit <= 0 {
+		return
+	}
+
+	t := now()
+	switch breakerState {
+	case BreakerHalfOpen:
+		breakerState, breakerUntil = BreakerOpen, t.Add(breakerCooldown)
+	case BreakerClosed:
+		if t.Sub(breakerLast) >= breakerCooldown {
+			breakerFailures = 0
+		}
+		breakerFailures++
+		breakerLast = t
+		if breakerFailures >= breakerLimit {
+			breakerState, breakerUntil = BreakerOpen, t.Add(breakerCooldown)
+			breakerFailures = 0
+		}
+	}
+}
+
+func getBreakerState() BreakerState {
+	breakerMtx.Lock()
+	defer breakerMtx.Unlock()
+	return breakerState
+}
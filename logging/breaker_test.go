@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t0 := time.Now()
+	tm := t0
+	SetClock(func() time.Time { return tm })
+	defer SetClock(nil)
+	defer SetCircuitBreaker(0, 0)
+
+	err := errors.New("unavailable")
+
+	// Disabled, errors don't open the circuit.
+	SetCircuitBreaker(0, 0)
+	for i := 0; i < 10; i++ {
+		onClientError(err)
+	}
+	if !breakerAllow() || getBreakerState() != BreakerClosed {
+		t.Fatal("disabled breaker opened")
+	}
+
+	SetCircuitBreaker(3, time.Minute)
+	onClientError(err)
+	onClientError(err)
+	if !breakerAllow() {
+		t.Fatal("opened before the limit")
+	}
+	onClientError(err)
+	if breakerAllow() || getBreakerState() != BreakerOpen {
+		t.Fatal("didn't open at the limit")
+	}
+
+	tm = t0.Add(time.Minute)
+	if !breakerAllow() || getBreakerState() != BreakerHalfOpen {
+		t.Fatal("not half-open after the cooldown")
+	}
+	onClientError(err)
+	if breakerAllow() || getBreakerState() != BreakerOpen {
+		t.Fatal("half-open didn't reopen on error")
+	}
+
+	tm = t0.Add(2 * time.Minute)
+	breakerAllow()
+	tm = t0.Add(3 * time.Minute)
+	if !breakerAllow() || getBreakerState() != BreakerClosed {
+		t.Fatal("didn't close after a cooldown without errors")
+	}
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+// An OverflowPolicy determines what happens when the buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block waits until there is room in the buffer.
+	Block OverflowPolicy = iota
+	// DropOldest drops the oldest buffered entry.
+	DropOldest
+	// DropNewest drops the entry being logged.
+	DropNewest
+)
+
+type queued struct {
+	nl    *NamedLogger
+	entry logging.Entry
+	done  chan struct{}
+}
+
+var (
+	bufferMtx sync.RWMutex
+	buffer    chan queued
+	policy    OverflowPolicy
+	dropped   uint64
+)
+
+// SetBufferPolicy sets up a bounded buffer of entries,
+// that a background goroutine forwards to the logging client,
+// so that a slow logging API never blocks the caller.
+// A size of zero (the default) disables the buffer.
+//
+// Flush and Close wait for buffered entries to be forwarded.
+// Entries redirected with SetOutput are written synchronously.
+func SetBufferPolicy(size int, p OverflowPolicy) {
+	var ch chan queued
+	if size > 0 {
+		ch = make(chan queued, size)
+		go forward(ch)
+	}
+
+	bufferMtx.Lock()
+	old := buffer
+	buffer, policy = ch, p
+	bufferMtx.Unlock()
+
+	if old != nil {
+		close(old)
+	}
+}
+
+func forward(ch chan queued) {
+	for q := range ch {
+		if q.done != nil {
+			close(q.done)
+		} else {
+			emit(q.nl, q.entry)
+		}
+	}
+}
+
+// enqueue reports whether the entry was handled by the buffer.
+// Entries redirected with SetOutput are never buffered,
+// so tests can assert on them immediately.
+func enqueue(nl *NamedLogger, entry logging.Entry) bool {
+	if hasOutput() {
+		return false
+	}
+
+	bufferMtx.RLock()
+	defer bufferMtx.RUnlock()
+	if buffer == nil {
+		return false
+	}
+
+	q := queued{nl: nl, entry: entry}
+	switch policy {
+	case DropNewest:
+		select {
+		case buffer <- q:
+		default:
+			atomic.AddUint64(&dropped, 1)
+		}
+
+	case DropOldest:
+		for {
+			select {
+			case buffer <- q:
+				return true
+			default:
+			}
+			select {
+			case old := <-buffer:
+				if old.done != nil {
+					close(old.done)
+				} else {
+					atomic.AddUint64(&dropped, 1)
+				}
+			default:
+			}
+		}
+
+	default:
+		buffer <- q
+	}
+	return true
+}
+
+// drain waits for buffered entries to be forwarded.
+func drain() {
+	bufferMtx.RLock()
+	ch := buffer
+	if ch != nil {
+		done := make(chan struct{})
+		ch <- queued{done: done}
+		bufferMtx.RUnlock()
+		<-done
+		return
+	}
+	bufferMtx.RUnlock()
+}
+
+// Statistics are counters kept by the package.
+type Statistics struct {
+	// Dropped is the number of entries dropped because the buffer was full.
+	Dropped uint64
+	// Breaker is the state of the circuit breaker; see SetCircuitBreaker.
+	Breaker BreakerState
+}
+
+// Stats gets the current Statistics.
+func Stats() Statistics {
+	return Statistics{
+		Dropped: atomic.LoadUint64(&dropped),
+		Breaker: getBreakerState(),
+	}
+}
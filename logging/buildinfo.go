@@ -0,0 +1,21 @@
+//go:build go1.18
+// +build go1.18
+
+package logging
+
+import "runtime/debug"
+
+func readBuildInfo() (info buildInfo) {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.goVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.revision = s.Value
+			case "vcs.time":
+				info.time = s.Value
+			}
+		}
+	}
+	return info
+}
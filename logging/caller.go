@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+const (
+	pkgPath   = "github.com/ncruces/go-gcf/logging"
+	pkgPrefix = pkgPath + "."
+)
+
+var reportCaller int32
+
+// SetReportCaller enables or disables reporting the source location
+// (file, line and function) of the code that logged each entry.
+//
+// The caller is the first frame outside this package; see WithCallerSkip.
+func SetReportCaller(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&reportCaller, v)
+}
+
+// WithCallerSkip gets a Logger that skips n additional frames
+// when reporting the source location of entries,
+// so that thin wrappers around this package can report their caller.
+// Skips accumulate over nested calls.
+func (l Logger) WithCallerSkip(n int) Logger {
+	l.skip += n
+	return l
+}
+
+// callerLocation gets the source location of the first frame
+// outside this package and the runtime, and outside any of the packages, after skip frames.
+func callerLocation(skip int, packages ...string) *logpb.LogEntrySourceLocation {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if !inPackage(f.Function, packages) {
+			if skip <= 0 {
+				return frameLocation(f)
+			}
+			skip--
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func inPackage(function string, packages []string) bool {
+	if strings.HasPrefix(function, pkgPrefix) || strings.HasPrefix(function, "runtime.") {
+		return true
+	}
+	for _, p := range packages {
+		if strings.HasPrefix(function, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pcLocation gets the source location of a program counter
+// (example: slog.Record.PC).
+func pcLocation(pc uintptr) *logpb.LogEntrySourceLocation {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frameLocation(f)
+}
+
+func frameLocation(f runtime.Frame) *logpb.LogEntrySourceLocation {
+	return &logpb.LogEntrySourceLocation{
+		File:     f.File,
+		Line:     int64(f.Line),
+		Function: f.Function,
+	}
+}
+
+// reportingCaller reports whether SetReportCaller is enabled.
+func reportingCaller() bool {
+	return atomic.LoadInt32(&reportCaller) != 0
+}
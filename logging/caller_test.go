@@ -0,0 +1,103 @@
+package logging_test
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ncruces/go-gcf/logging"
+	"github.com/ncruces/go-gcf/logging/loggingtest"
+)
+
+// These tests are outside package logging, whose frames aren't reported.
+
+func checkCaller(t *testing.T, function string) {
+	t.Helper()
+	entries := loggingtest.Entries()
+	if len(entries) == 0 {
+		t.Fatal("no entries")
+	}
+	loc := entries[len(entries)-1].SourceLocation
+	if loc == nil {
+		t.Fatal("no source location")
+	}
+	if !strings.HasSuffix(loc.File, "caller_test.go") || loc.Function != "github.com/ncruces/go-gcf/logging_test."+function {
+		t.Errorf("source location = %s:%d %s, want %s", loc.File, loc.Line, loc.Function, function)
+	}
+}
+
+func reportCaller() func() {
+	loggingtest.Reset()
+	logging.SetFallback(logging.FallbackDiscard)
+	logging.SetReportCaller(true)
+	return func() {
+		logging.SetReportCaller(false)
+		logging.SetFallback(logging.FallbackStdout)
+	}
+}
+
+func panicking() {
+	defer logging.RecoverAndLog(nil)
+	panic("boom")
+}
+
+func panickingNil() {
+	defer logging.RecoverAndLog(nil)
+	var m map[string]int
+	m["boom"]++
+}
+
+func recoverError() (err error) {
+	defer logging.RecoverError(nil, &err)
+	panic("boom")
+}
+
+func TestCaller_recover(t *testing.T) {
+	defer reportCaller()()
+
+	panicking()
+	checkCaller(t, "panicking")
+
+	panickingNil()
+	checkCaller(t, "panickingNil")
+
+	recoverError()
+	checkCaller(t, "recoverError")
+}
+
+func TestCaller_handler(t *testing.T) {
+	defer reportCaller()()
+
+	h := logging.Handler(http.NotFoundHandler())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	checkCaller(t, "TestCaller_handler")
+}
+
+func TestCaller_transport(t *testing.T) {
+	defer reportCaller()()
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	client := http.Client{Transport: logging.LogTransport(nil, nil)}
+	if res, err := client.Get(srv.URL); err == nil {
+		res.Body.Close()
+	}
+	checkCaller(t, "TestCaller_transport")
+}
+
+func TestCaller_writer(t *testing.T) {
+	defer reportCaller()()
+
+	log.New(logging.Info(nil).Writer(), "", 0).Print("legacy")
+	checkCaller(t, "TestCaller_writer")
+}
+
+func TestCaller_direct(t *testing.T) {
+	defer reportCaller()()
+
+	logging.Info(nil).Print("direct")
+	checkCaller(t, "TestCaller_direct")
+}
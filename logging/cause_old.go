@@ -0,0 +1,10 @@
+//go:build !go1.20
+// +build !go1.20
+
+package logging
+
+import "context"
+
+func cause(ctx context.Context) error {
+	return ctx.Err()
+}
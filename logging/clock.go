@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var clock atomic.Value
+
+// SetClock sets the function used to get the current time,
+// which allows tests to control time. A nil function restores time.Now.
+func SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	clock.Store(now)
+}
+
+func now() time.Time {
+	if now, ok := clock.Load().(func() time.Time); ok {
+		return now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,40 @@
+package logging
+
+import "reflect"
+
+// Collection logs a summary of a slice or array: the first sample items,
+// as the name field, and the total length, as the name_count field.
+// The message is the name.
+//
+// Other values are logged in full, as the name field,
+// with a Debug entry noting the misuse.
+func (l Logger) Collection(name string, items interface{}, sample int) {
+	if l.noop {
+		return
+	}
+
+	v := reflect.ValueOf(items)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		Debug(l.ctx).Printf("logging: Collection called with %T, not a slice", items)
+		l.WithFields(map[string]interface{}{name: items}).log(name)
+		return
+	}
+
+	n := v.Len()
+	if sample < 0 {
+		sample = 0
+	}
+	if sample > n {
+		sample = n
+	}
+	head := make([]interface{}, sample)
+	for i := range head {
+		head[i] = v.Index(i).Interface()
+	}
+	l.WithFields(map[string]interface{}{
+		name:            head,
+		name + "_count": n,
+	}).log(name)
+}
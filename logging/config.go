@@ -0,0 +1,230 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/template"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+const defaultLogName = "cloudfunctions.googleapis.com/cloud-functions"
+
+// These are guarded by clientMtx.
+var (
+	logName        = defaultLogName
+	customResource *monitoredres.MonitoredResource
+	endpoint       string
+)
+
+// configMtx serializes configuration changes.
+var configMtx sync.Mutex
+
+// Options is a full configuration of the package; see Configure.
+// The zero value is the default configuration.
+type Options struct {
+	// MinLevel is the minimum severity of entries to log; see SetMinLevel.
+	MinLevel logging.Severity
+	// SamplingRate is the fraction of entries to keep; see SetSamplingRate.
+	// Zero means 1 (keep all).
+	SamplingRate float64
+	// SamplingRates, if not nil, override SamplingRate per severity; see SetSamplingRates.
+	SamplingRates map[logging.Severity]float64
+	// DefaultLabels are added to every entry; see SetDefaultLabels.
+	DefaultLabels map[string]string
+
+	// LogName is the log ID entries are written to; see SetLogName.
+	LogName string
+	// Resource is the monitored resource of entries; see SetResource.
+	Resource *monitoredres.MonitoredResource
+	// Endpoint overrides the Cloud Logging API endpoint.
+	// LOGGING_EMULATOR_HOST takes precedence.
+	Endpoint string
+}
+
+// Configure applies a full configuration, under a single lock,
+// so concurrent configuration changes don't interleave.
+// The individual setters remain available, for convenience.
+//
+// If the log name, resource or endpoint changed,
+// Configure rebuilds the logging client, unless it was closed; see Close.
+func Configure(opts Options) {
+	if opts.SamplingRate == 0 {
+		opts.SamplingRate = 1
+	}
+
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setMinLevel(opts.MinLevel)
+	setSamplingRate(opts.SamplingRate)
+	setSamplingRates(opts.SamplingRates)
+	setDefaultLabels(opts.DefaultLabels)
+	reconfigure(opts.LogName, opts.Resource, opts.Endpoint)
+}
+
+// LoadConfig reads a JSON configuration, and applies it. Example:
+//
+//	{
+//		"min_level": "INFO",
+//		"format": "{{.Severity}} {{.Message}}",
+//		"redacted_fields": ["password", "token"],
+//		"sampling_rate": 0.1,
+//		"default_labels": {"team": "payments"}
+//	}
+//
+// Settings missing from the configuration are left unchanged;
+// sampling_rate clears the rates set by SetSamplingRates.
+// If any setting is invalid, an error is returned and none are applied.
+func LoadConfig(r io.Reader) error {
+	var cfg struct {
+		MinLevel       *string           `json:"min_level"`
+		Format         *string           `json:"format"`
+		RedactedFields []string          `json:"redacted_fields"`
+		SamplingRate   *float64          `json:"sampling_rate"`
+		DefaultLabels  map[string]string `json:"default_labels"`
+	}
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return fmt.Errorf("logging: invalid config: %v", err)
+	}
+
+	var min logging.Severity
+	if cfg.MinLevel != nil {
+		s, err := ParseSeverity(*cfg.MinLevel)
+		if err != nil {
+			return fmt.Errorf("logging: invalid config min_level: %q", *cfg.MinLevel)
+		}
+		min = s
+	}
+	var tmpl *template.Template
+	if cfg.Format != nil && *cfg.Format != "" {
+		t, err := template.New("format").Parse(*cfg.Format)
+		if err != nil {
+			return fmt.Errorf("logging: invalid config format: %v", err)
+		}
+		tmpl = t
+	}
+	if cfg.SamplingRate != nil && !(*cfg.SamplingRate >= 0 && *cfg.SamplingRate <= 1) {
+		return errors.New("logging: invalid config sampling_rate: must be between 0 and 1")
+	}
+
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	if cfg.MinLevel != nil {
+		setMinLevel(min)
+	}
+	if cfg.Format != nil {
+		formatMtx.Lock()
+		format = tmpl
+		formatMtx.Unlock()
+	}
+	if cfg.RedactedFields != nil {
+		setRedactedFields(cfg.RedactedFields)
+	}
+	if cfg.SamplingRate != nil {
+		setSamplingRate(*cfg.SamplingRate)
+	}
+	if cfg.DefaultLabels != nil {
+		setDefaultLabels(cfg.DefaultLabels)
+	}
+	return nil
+}
+
+// SetLogName sets the log ID entries are written to
+// (default: "cloudfunctions.googleapis.com/cloud-functions").
+func SetLogName(name string) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+
+	clientMtx.RLock()
+	res, ep := customResource, endpoint
+	clientMtx.RUnlock()
+	reconfigure(name, res, ep)
+}
+
+// SetResource sets the monitored resource of entries,
+// which otherwise is a cloud_function resource, built from the environment.
+func SetResource(res *monitoredres.MonitoredResource) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+
+	clientMtx.RLock()
+	name, ep := logName, endpoint
+	clientMtx.RUnlock()
+	reconfigure(name, res, ep)
+}
+
+// reconfigure rebuilds the logging client, if needed, unless closed.
+// It must be called with configMtx held.
+//
+// A new client is created before taking clientMtx, so logging isn't blocked meanwhile.
+// Entries logged during the swap go to the new loggers,
+// and the old ones are flushed (or their client closed) after the swap,
+// so no entries are lost.
+func reconfigure(name string, res *monitoredres.MonitoredResource, ep string) {
+	if name == "" {
+		name = defaultLogName
+	}
+
+	setup()
+	clientMtx.RLock()
+	same := name == logName && res == customResource && ep == endpoint && client != nil
+	reconnect := ep != endpoint || client == nil
+	done := closed
+	clientMtx.RUnlock()
+	if same || done {
+		return
+	}
+
+	var c *logging.Client
+	var r *monitoredres.MonitoredResource
+	var err error
+	if reconnect {
+		c, r, err = connect(res, ep)
+	} else {
+		r, err = newResource(res)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to create logging client:", err)
+		return
+	}
+
+	clientMtx.Lock()
+	if closed || c == nil && client == nil {
+		// Closed meanwhile.
+		clientMtx.Unlock()
+		if c != nil {
+			c.Close()
+		}
+		return
+	}
+	logName, customResource, endpoint = name, res, ep
+	old := []*logging.Logger{logger}
+	var oldClient *logging.Client
+	if c != nil {
+		oldClient, client = client, c
+	}
+	resource = r
+	logger = client.Logger(logName, logging.CommonResource(resource))
+	for _, nl := range namedLoggers() {
+		old = append(old, nl.reset())
+	}
+	clientMtx.Unlock()
+
+	if oldClient != nil {
+		oldClient.Close()
+		return
+	}
+	for _, l := range old {
+		if l != nil {
+			l.Flush()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+	"google.golang.org/grpc"
+)
+
+// fakeLogging is a Cloud Logging server that records entry messages.
+type fakeLogging struct {
+	logpb.UnimplementedLoggingServiceV2Server
+	mtx      sync.Mutex
+	messages map[string]int
+}
+
+func (f *fakeLogging) WriteLogEntries(_ context.Context, req *logpb.WriteLogEntriesRequest) (*logpb.WriteLogEntriesResponse, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, e := range req.Entries {
+		msg := e.GetTextPayload()
+		if p := e.GetJsonPayload(); p != nil {
+			msg = p.Fields["message"].GetStringValue()
+		}
+		f.messages[msg]++
+	}
+	return &logpb.WriteLogEntriesResponse{}, nil
+}
+
+func startFakeLogging(t *testing.T) (*fakeLogging, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeLogging{messages: map[string]int{}}
+	srv := grpc.NewServer()
+	logpb.RegisterLoggingServiceV2Server(srv, fake)
+	go srv.Serve(lis)
+
+	env := map[string]string{
+		"GOOGLE_CLOUD_PROJECT":  "test-project",
+		"LOGGING_EMULATOR_HOST": lis.Addr().String(),
+		"FUNCTION_NAME":         "test-function",
+		"FUNCTION_REGION":       "test-region",
+	}
+	var restore []func()
+	for k, v := range env {
+		k, old, ok := k, os.Getenv(k), os.Getenv(k) != ""
+		os.Setenv(k, v)
+		restore = append(restore, func() {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+	setup()
+	clientMtx.Lock()
+	closed = false
+	if client == nil {
+		c, r, err := connect(customResource, endpoint)
+		if err != nil {
+			clientMtx.Unlock()
+			t.Fatal(err)
+		}
+		client, resource = c, r
+		logger = client.Logger(logName, logging.CommonResource(resource))
+	}
+	clientMtx.Unlock()
+
+	return fake, func() {
+		Close()
+		srv.Stop()
+		clientMtx.Lock()
+		logName, customResource, endpoint = defaultLogName, nil, ""
+		closed = false
+		clientMtx.Unlock()
+		for _, r := range restore {
+			r()
+		}
+	}
+}
+
+func TestReconfigure_noLostEntries(t *testing.T) {
+	fake, stop := startFakeLogging(t)
+	defer stop()
+
+	res := &monitoredres.MonitoredResource{Type: "global"}
+	Configure(Options{Resource: res})
+
+	const goroutines, entries = 8, 250
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				Info(nil).Printf("entry %d-%d", g, i)
+			}
+		}(g)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprint("log-", i%2)
+			if i%3 == 0 {
+				// A different endpoint reconnects; the emulator host takes precedence.
+				Configure(Options{LogName: name, Resource: res, Endpoint: fmt.Sprint("endpoint-", i)})
+			} else {
+				SetLogName(name)
+			}
+		}
+	}()
+
+	wg.Wait()
+	<-done
+	if err := Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	for g := 0; g < goroutines; g++ {
+		for i := 0; i < entries; i++ {
+			if n := fake.messages[fmt.Sprintf("entry %d-%d", g, i)]; n != 1 {
+				t.Errorf("entry %d-%d logged %d times", g, i, n)
+			}
+		}
+	}
+}
+
+func TestReconfigure_resourceError(t *testing.T) {
+	_, stop := startFakeLogging(t)
+	defer stop()
+
+	res := &monitoredres.MonitoredResource{Type: "global"}
+	SetResource(res)
+
+	// Without FUNCTION_NAME, the default resource can't be built.
+	name := os.Getenv("FUNCTION_NAME")
+	os.Unsetenv("FUNCTION_NAME")
+	defer os.Setenv("FUNCTION_NAME", name)
+	SetResource(nil)
+
+	clientMtx.RLock()
+	defer clientMtx.RUnlock()
+	if customResource != res || resource != res {
+		t.Errorf("resource = %v, want %v", resource, res)
+	}
+}
+
+func TestReconfigure_afterClose(t *testing.T) {
+	_, stop := startFakeLogging(t)
+	defer stop()
+
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	SetLogName("after-close")
+	Configure(Options{Resource: &monitoredres.MonitoredResource{Type: "global"}, Endpoint: "other"})
+
+	clientMtx.RLock()
+	defer clientMtx.RUnlock()
+	if client != nil || logger != nil {
+		t.Error("client created after Close")
+	}
+	if logName != defaultLogName {
+		t.Errorf("log name = %q, after Close", logName)
+	}
+}
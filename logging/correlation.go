@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"context"
+
+	"cloud.google.com/go/functions/metadata"
+)
+
+// CopyCorrelation copies the logging correlation of src onto dst:
+// the execution ID, event metadata, trace, span, sampling decision, start time,
+// labels, fields and diagnostic fields (see Attach).
+// Cancellation and other values of src are not copied.
+//
+// It lets workers with their own long lived Context log under the request
+// that originated each job:
+//
+//	ctx := logging.CopyCorrelation(req, workerCtx)
+//
+// Labels and fields of src take precedence over those of dst.
+// Execution IDs from SetExecutionIDFromContext are not copied.
+func CopyCorrelation(src, dst context.Context) context.Context {
+	if dst == nil {
+		dst = context.Background()
+	}
+	if src == nil {
+		return dst
+	}
+
+	if meta, _ := metadata.FromContext(src); meta != nil {
+		dst = metadata.NewContext(dst, meta)
+	}
+	for _, key := range []interface{}{
+		contextKey{}, drawKey{}, startKey{}, traceKey{}, spanKey{}, attachKey{},
+	} {
+		if v := src.Value(key); v != nil {
+			dst = context.WithValue(dst, key, v)
+		}
+	}
+	if l, ok := src.Value(labelsKey{}).(map[string]string); ok {
+		dst = WithLabels(dst, l)
+	}
+	if f, ok := src.Value(fieldsKey{}).(map[string]interface{}); ok {
+		dst = WithFieldsContext(dst, f)
+	}
+	return dst
+}
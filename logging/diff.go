@@ -0,0 +1,71 @@
+package logging
+
+import "reflect"
+
+// Diff logs the fields changed between two values of the same struct type,
+// as the name field, mapping each changed field to its before and after values.
+// Nested structs are compared field by field; the message is the name.
+//
+// Unexported fields, functions and channels are skipped.
+// Values that aren't structs of the same type are logged in full, if different.
+func (l Logger) Diff(name string, before, after interface{}) {
+	if l.noop {
+		return
+	}
+	d := diff(reflect.ValueOf(before), reflect.ValueOf(after))
+	if d == nil {
+		d = map[string]interface{}{}
+	}
+	l.WithFields(map[string]interface{}{name: d}).log(name)
+}
+
+func diff(a, b reflect.Value) interface{} {
+	for a.Kind() == reflect.Ptr && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Ptr && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	if a.IsValid() && b.IsValid() && a.Type() == b.Type() {
+		switch a.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			return nil
+		case reflect.Struct:
+			var res map[string]interface{}
+			t := a.Type()
+			for i := 0; i < t.NumField(); i++ {
+				if t.Field(i).PkgPath != "" {
+					continue // unexported
+				}
+				if d := diff(a.Field(i), b.Field(i)); d != nil {
+					if res == nil {
+						res = map[string]interface{}{}
+					}
+					res[t.Field(i).Name] = d
+				}
+			}
+			if res == nil {
+				return nil
+			}
+			return res
+		}
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+	} else if !a.IsValid() && !b.IsValid() {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"before": value(a),
+		"after":  value(b),
+	}
+}
+
+func value(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return normalize(v.Interface())
+}
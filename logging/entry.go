@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+// An EntryBuilder builds an entry, with full control over its fields.
+// Correlation with the Logger's Context is filled in.
+//
+//	logging.Info(ctx).Entry().Message("done").Field("items", n).Emit()
+type EntryBuilder struct {
+	l        Logger
+	msg      string
+	labels   map[string]string
+	time     time.Time
+	trace    string
+	insertID string
+}
+
+// Entry gets an EntryBuilder for the Logger.
+func (l Logger) Entry() *EntryBuilder {
+	return &EntryBuilder{l: l}
+}
+
+// Message sets the message.
+func (b *EntryBuilder) Message(s string) *EntryBuilder {
+	b.msg = s
+	return b
+}
+
+// Field adds a structured field; see Logger.WithFields.
+func (b *EntryBuilder) Field(key string, value interface{}) *EntryBuilder {
+	b.l = b.l.WithFields(map[string]interface{}{key: value})
+	return b
+}
+
+// Label adds a label, which takes precedence over labels set at all other levels.
+func (b *EntryBuilder) Label(key, value string) *EntryBuilder {
+	if b.labels == nil {
+		b.labels = map[string]string{}
+	}
+	b.labels[key] = value
+	return b
+}
+
+// Time sets the timestamp.
+func (b *EntryBuilder) Time(t time.Time) *EntryBuilder {
+	b.time = t
+	return b
+}
+
+// Trace sets the trace resource name (example: "projects/my-project/traces/06796866738c859f2f19b7cfb3214824").
+func (b *EntryBuilder) Trace(trace string) *EntryBuilder {
+	b.trace = trace
+	return b
+}
+
+// InsertID sets the unique ID used to deduplicate the entry.
+func (b *EntryBuilder) InsertID(id string) *EntryBuilder {
+	b.insertID = id
+	return b
+}
+
+// Emit logs the entry.
+func (b *EntryBuilder) Emit() {
+	b.l.logWith(b.msg, func(e *logging.Entry) {
+		if len(b.labels) > 0 {
+			e.Labels = mergeLabels(e.Labels, b.labels)
+		}
+		if !b.time.IsZero() {
+			e.Timestamp = b.time
+		}
+		if b.trace != "" {
+			e.Trace = b.trace
+		}
+		if b.insertID != "" {
+			e.InsertID = b.insertID
+		}
+	})
+}
+
+const reservedPrefix = "logging.googleapis.com/"
+
+// isReserved reports whether the key is reserved by Cloud Logging
+// for an entry field that setReserved can set.
+func isReserved(key string) bool {
+	switch key {
+	case reservedPrefix + "trace",
+		reservedPrefix + "spanId",
+		reservedPrefix + "trace_sampled",
+		reservedPrefix + "insertId":
+		return true
+	}
+	return false
+}
+
+// setReserved sets the entry field for a key reserved by Cloud Logging.
+func setReserved(e *logging.Entry, key, value string) {
+	switch key {
+	case reservedPrefix + "trace":
+		e.Trace = value
+	case reservedPrefix + "spanId":
+		e.SpanID = value
+	case reservedPrefix + "trace_sampled":
+		e.TraceSampled = value == "true"
+	case reservedPrefix + "insertId":
+		e.InsertID = value
+	}
+}
+
+// An Entry is a prepared entry, for LogAll and LogBatch.
+type Entry struct {
+	Severity logging.Severity
+	Message  string
+	Fields   map[string]interface{}
+	Labels   map[string]string
+}
+
+// LogAll logs prepared entries, in order, with the Context.
+// The Context's correlation is read once, and shared by all entries.
+// Entries are filtered and sampled individually.
+func LogAll(ctx context.Context, entries []Entry) {
+	base := newLogger(ctx, logging.Default)
+	for _, e := range entries {
+		l := base
+		l.s = e.Severity
+		l.WithFields(e.Fields).WithLabels(e.Labels).log(e.Message)
+	}
+}
+
+// LogBatch is like LogAll, but groups entries as an operation,
+// and coalesces labels common to all entries.
+//
+// An operation entry, with the highest severity of the batch, and the common labels,
+// is logged first; entries follow, with only the labels that differ.
+// Entries are grouped in Logs Explorer by operation ID.
+func LogBatch(ctx context.Context, entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	common := mergeLabels(entries[0].Labels)
+	max := entries[0].Severity
+	for _, e := range entries[1:] {
+		for k, v := range common {
+			if w, ok := e.Labels[k]; !ok || w != v {
+				delete(common, k)
+			}
+		}
+		if e.Severity > max {
+			max = e.Severity
+		}
+	}
+
+	id := newUUID()
+	base := newLogger(ctx, max)
+
+	l := base.WithLabels(common)
+	l.logWith(fmt.Sprintf("batch of %d entries", len(entries)), func(e *logging.Entry) {
+		e.Operation = &logpb.LogEntryOperation{Id: id, Producer: pkgPath, First: true}
+	})
+
+	for i, e := range entries {
+		labels := make(map[string]string, len(e.Labels))
+		for k, v := range e.Labels {
+			if _, ok := common[k]; !ok {
+				labels[k] = v
+			}
+		}
+		l := base
+		l.s = e.Severity
+		last := i == len(entries)-1
+		l.WithFields(e.Fields).WithLabels(labels).logWith(e.Message, func(e *logging.Entry) {
+			e.Operation = &logpb.LogEntryOperation{Id: id, Producer: pkgPath, Last: last}
+		})
+	}
+}
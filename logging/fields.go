@@ -0,0 +1,261 @@
+package logging
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// WithFields gets a Logger that logs structured entries,
+// with the fields added to the payload, alongside the message.
+// Fields accumulate over nested calls.
+//
+// Values that don't marshal cleanly to JSON are normalized:
+// times are formatted as RFC 3339, errors and fmt.Stringers as strings,
+// and byte slices as strings if valid UTF-8, or base64 otherwise.
+func (l Logger) WithFields(fields map[string]interface{}) Logger {
+	if l.noop {
+		return l
+	}
+	for _, v := range fields {
+		if err, ok := v.(error); ok && !isNil(err) {
+			l.hasErr = true
+		}
+	}
+	l.fields = addFields(l.fields, fields)
+	return l
+}
+
+// withBuiltins is like WithFields, for fields added by this package,
+// which aren't subject to the schema (see RegisterSchema),
+// and whose errors don't promote entries (see SetPromoteOnError).
+func (l Logger) withBuiltins(fields map[string]interface{}) Logger {
+	if l.noop {
+		return l
+	}
+	l.builtins = addFields(l.builtins, fields)
+	return l
+}
+
+var promoteOnError int32
+
+var durationUnit = int64(time.Millisecond)
+
+// SetDurationUnit sets the unit (example: time.Second) of durations
+// added by WithDuration, which are logged as a float number of units
+// (default: time.Millisecond).
+func SetDurationUnit(unit time.Duration) {
+	if unit <= 0 {
+		unit = time.Millisecond
+	}
+	atomic.StoreInt64(&durationUnit, int64(unit))
+}
+
+// WithDuration gets a Logger that adds the duration as a field,
+// in the unit set by SetDurationUnit.
+func (l Logger) WithDuration(name string, d time.Duration) Logger {
+	if l.noop {
+		return l
+	}
+	unit := atomic.LoadInt64(&durationUnit)
+	return l.WithFields(map[string]interface{}{name: float64(d) / float64(unit)})
+}
+
+// Tmpl logs a message with {key} placeholders replaced by the fields,
+// which are also added to the payload, as with WithFields:
+//
+//	logging.Info(ctx).Tmpl("user {user_id} did {action}", fields)
+//
+// Placeholders missing from the fields are left as is.
+func (l Logger) Tmpl(template string, fields map[string]interface{}) {
+	if l.noop {
+		return
+	}
+
+	var buf strings.Builder
+	for {
+		i := strings.IndexByte(template, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(template[i:], '}')
+		if j < 0 {
+			break
+		}
+		buf.WriteString(template[:i])
+		key := template[i+1 : i+j]
+		if v, ok := fields[key]; ok {
+			fmt.Fprint(&buf, normalize(v))
+		} else {
+			buf.WriteString(template[i : i+j+1])
+		}
+		template = template[i+j+1:]
+	}
+	buf.WriteString(template)
+	l.WithFields(fields).log(buf.String())
+}
+
+// SetPromoteOnError enables or disables promoting entries to Error,
+// if any of their structured fields is a non-nil error.
+// Errors in fields added by this package (example: by LogContextEnd) don't promote entries.
+func SetPromoteOnError(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&promoteOnError, v)
+}
+
+type fieldsKey struct{}
+
+// WithFieldsContext creates a Context that adds structured fields
+// to every entry logged with it; see Logger.WithFields.
+// Fields accumulate over nested Contexts,
+// and fields set with Logger.WithFields take precedence.
+func WithFieldsContext(ctx context.Context, fields map[string]interface{}) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	outer, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	return context.WithValue(ctx, fieldsKey{}, addFields(outer, fields))
+}
+
+// addFields merges normalized fields into a new map.
+func addFields(fields, add map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(fields)+len(add))
+	for k, v := range fields {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[k] = normalize(v)
+	}
+	return merged
+}
+
+func normalize(v interface{}) interface{} {
+	switch v.(type) {
+	case error, fmt.Stringer:
+		if isNil(v) {
+			return "<nil>"
+		}
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	}
+	return v
+}
+
+// isNil reports whether v is nil, or a typed nil pointer, map, slice, etc.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch r := reflect.ValueOf(v); r.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return r.IsNil()
+	}
+	return false
+}
+
+var msgKey atomic.Value
+
+// SetMessageKey sets the key of the message in structured payloads,
+// and in JSON output; see SetOutput (default: "message").
+//
+// Logs Explorer and the logging agent only recognize the default key.
+func SetMessageKey(key string) {
+	if key == "" {
+		key = "message"
+	}
+	msgKey.Store(key)
+}
+
+// MessageKey gets the key of the message in structured payloads; see SetMessageKey.
+// Hooks use it to find the message of entries; see AddHook.
+func MessageKey() string {
+	if k, ok := msgKey.Load().(string); ok {
+		return k
+	}
+	return "message"
+}
+
+func payload(msg string, fields map[string]interface{}) interface{} {
+	if len(fields) == 0 {
+		return msg
+	}
+	p := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		p[k] = v
+	}
+	p[MessageKey()] = msg
+	return p
+}
+
+func unpayload(p interface{}) (msg string, fields map[string]interface{}) {
+	if m, ok := p.(map[string]interface{}); ok {
+		key := MessageKey()
+		fields = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			if k == key {
+				msg = fmt.Sprint(v)
+			} else {
+				fields[k] = v
+			}
+		}
+		return msg, fields
+	}
+	return fmt.Sprint(p), nil
+}
+
+var redacted atomic.Value
+
+// SetRedactedFields sets structured field keys whose values are
+// replaced with "REDACTED" before entries are logged.
+func SetRedactedFields(keys ...string) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setRedactedFields(keys)
+}
+
+func setRedactedFields(keys []string) {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	redacted.Store(set)
+}
+
+func redactFields(fields map[string]interface{}) map[string]interface{} {
+	set, _ := redacted.Load().(map[string]struct{})
+	if len(set) == 0 || len(fields) == 0 {
+		return fields
+	}
+	var res map[string]interface{}
+	for k := range set {
+		if _, ok := fields[k]; ok {
+			if res == nil {
+				res = addFields(fields, nil)
+			}
+			res[k] = "REDACTED"
+		}
+	}
+	if res == nil {
+		return fields
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+type nilErr struct{}
+
+func (*nilErr) Error() string { return "nilErr" }
+
+type nilStringer struct{}
+
+func (*nilStringer) String() string { return "nilStringer" }
+
+func TestNormalize_typedNil(t *testing.T) {
+	var e *nilErr
+	var s *nilStringer
+	tests := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"nil", nil, nil},
+		{"error", errors.New("boom"), "boom"},
+		{"typed nil error", e, "<nil>"},
+		{"typed nil stringer", s, "<nil>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalize(tt.in); got != tt.want {
+				t.Errorf("normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFields_typedNilError(t *testing.T) {
+	var e *nilErr
+	l := Info(nil).WithFields(map[string]interface{}{"err": e})
+	if l.hasErr {
+		t.Error("typed nil error set hasErr")
+	}
+	if got := l.fields["err"]; got != "<nil>" {
+		t.Errorf("field = %v, want <nil>", got)
+	}
+}
+
+func TestPromoteOnError(t *testing.T) {
+	SetPromoteOnError(true)
+	defer SetPromoteOnError(false)
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+
+	var got logging.Severity
+	defer addHook(func(_ context.Context, e logging.Entry) { got = e.Severity })()
+
+	Info(nil).WithFields(map[string]interface{}{"err": errors.New("boom")}).Print("caller error")
+	if got != logging.Error {
+		t.Errorf("caller error logged at %v, want Error", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	LogContextEnd(ctx)
+	if got != logging.Warning {
+		t.Errorf("canceled Context logged at %v, want Warning", got)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"time"
+)
+
+// flushTimeout bounds the flush of FlushOnDone.
+const flushTimeout = 5 * time.Second
+
+// FlushOnDone flushes all loggers, in the background, when the Context is done,
+// for functions that don't use the Handler middleware.
+// The flush is bounded to a few seconds.
+//
+// It requires Go 1.21 or above; with older versions, it does nothing.
+func FlushOnDone(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	context.AfterFunc(ctx, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+		FlushContext(ctx)
+	})
+}
@@ -0,0 +1,9 @@
+//go:build !go1.21
+// +build !go1.21
+
+package logging
+
+import "context"
+
+// FlushOnDone does nothing before Go 1.21.
+func FlushOnDone(ctx context.Context) {}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	gatewayMtx    sync.RWMutex
+	gatewayHeader string
+	gatewayClaims []string
+)
+
+// SetGatewayRequestIDHeader sets a request header, set by an API gateway
+// (example: "X-Request-Id"), that ForRequest uses as the correlation id,
+// when there is no execution id; see ExecutionID.
+func SetGatewayRequestIDHeader(name string) {
+	gatewayMtx.Lock()
+	gatewayHeader = name
+	gatewayMtx.Unlock()
+}
+
+// SetGatewayClaims sets the claims of the authenticated user that ForRequest
+// extracts from the X-Apigateway-Api-Userinfo header, set by API Gateway,
+// as labels prefixed by "user_" (example: "sub" and "email" become user_sub and user_email).
+//
+// Only the listed claims are extracted, to avoid leaking sensitive ones.
+// No claims are extracted by default.
+func SetGatewayClaims(claims ...string) {
+	gatewayMtx.Lock()
+	gatewayClaims = claims
+	gatewayMtx.Unlock()
+}
+
+func gatewayRequestID(r *http.Request) string {
+	gatewayMtx.RLock()
+	h := gatewayHeader
+	gatewayMtx.RUnlock()
+	if h == "" {
+		return ""
+	}
+	return r.Header.Get(h)
+}
+
+func gatewayLabels(r *http.Request, labels map[string]string) {
+	gatewayMtx.RLock()
+	claims := gatewayClaims
+	gatewayMtx.RUnlock()
+	if len(claims) == 0 {
+		return
+	}
+
+	info := r.Header.Get("X-Apigateway-Api-Userinfo")
+	if info == "" {
+		return
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(info, "="))
+	if err != nil {
+		return
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(buf, &m) != nil {
+		return
+	}
+	for _, c := range claims {
+		switch v := m[c].(type) {
+		case string:
+			labels["user_"+c] = v
+		case float64, bool:
+			labels["user_"+c] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+// GELFWriter writes a copy of every entry logged to the writer,
+// as newline delimited GELF (Graylog Extended Log Format) messages,
+// for pipelines outside Google Cloud. It returns a function that stops it.
+//
+// Structured fields and labels become additional fields,
+// and severities are mapped to syslog levels.
+// Entries are still sent to Cloud Logging, as usual.
+func GELFWriter(w io.Writer) func() {
+	var mtx sync.Mutex
+	host, _ := os.Hostname()
+
+	return addHook(func(_ context.Context, entry logging.Entry) {
+		buf, err := json.Marshal(gelfMessage(host, entry))
+		if err != nil {
+			return
+		}
+		buf = append(buf, '\n')
+
+		mtx.Lock()
+		w.Write(buf)
+		mtx.Unlock()
+	})
+}
+
+func gelfMessage(host string, entry logging.Entry) map[string]interface{} {
+	msg, fields := unpayload(entry.Payload)
+
+	m := make(map[string]interface{}, len(fields)+len(entry.Labels)+8)
+	for k, v := range entry.Labels {
+		m[gelfField(k)] = v
+	}
+	for k, v := range fields {
+		m[gelfField(k)] = v
+	}
+	if entry.Trace != "" {
+		m["_trace"] = entry.Trace
+	}
+	if entry.SpanID != "" {
+		m["_span_id"] = entry.SpanID
+	}
+	m["_severity"] = SeverityString(entry.Severity)
+
+	m["version"] = "1.1"
+	m["host"] = host
+	m["short_message"] = msg
+	m["level"] = gelfLevel(entry.Severity)
+	if !entry.Timestamp.IsZero() {
+		m["timestamp"] = float64(entry.Timestamp.UnixNano()) / float64(time.Second)
+	}
+	return m
+}
+
+// gelfField makes an additional field name,
+// replacing characters GELF doesn't allow.
+func gelfField(key string) string {
+	buf := []byte("_" + key)
+	for i, c := range buf {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '_', c == '.', c == '-':
+		default:
+			buf[i] = '_'
+		}
+	}
+	if string(buf) == "_id" {
+		return "__id"
+	}
+	return string(buf)
+}
+
+func gelfLevel(s logging.Severity) int {
+	switch {
+	case s >= logging.Emergency:
+		return 0
+	case s >= logging.Alert:
+		return 1
+	case s >= logging.Critical:
+		return 2
+	case s >= logging.Error:
+		return 3
+	case s >= logging.Warning:
+		return 4
+	case s >= logging.Notice:
+		return 5
+	case s >= logging.Info:
+		return 6
+	case s >= logging.Debug:
+		return 7
+	}
+	return 6 // Default
+}
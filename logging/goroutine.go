@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	boundMtx   sync.RWMutex
+	bound      = map[uint64]context.Context{}
+	boundCount int32
+)
+
+// BindGoroutine associates the current goroutine with the Context,
+// so that entries logged from it with a nil Context use it instead.
+//
+// It's a discouraged, last resort fallback, for code that can't pass the Context:
+// finding the goroutine is slow, and each BindGoroutine must be paired
+// with an UnbindGoroutine, on the same goroutine, or the Context leaks:
+//
+//	logging.BindGoroutine(ctx)
+//	defer logging.UnbindGoroutine()
+func BindGoroutine(ctx context.Context) {
+	id := goid()
+	boundMtx.Lock()
+	if _, ok := bound[id]; !ok {
+		atomic.AddInt32(&boundCount, 1)
+	}
+	bound[id] = ctx
+	boundMtx.Unlock()
+}
+
+// UnbindGoroutine removes the association made by BindGoroutine
+// for the current goroutine.
+func UnbindGoroutine() {
+	id := goid()
+	boundMtx.Lock()
+	if _, ok := bound[id]; ok {
+		atomic.AddInt32(&boundCount, -1)
+		delete(bound, id)
+	}
+	boundMtx.Unlock()
+}
+
+// boundContext is cheap as long as no goroutine is bound.
+func boundContext() context.Context {
+	if atomic.LoadInt32(&boundCount) == 0 {
+		return nil
+	}
+	id := goid()
+	boundMtx.RLock()
+	ctx := bound[id]
+	boundMtx.RUnlock()
+	return ctx
+}
+
+// goid parses the current goroutine's ID from its stack trace header:
+// "goroutine 123 [running]:"
+func goid() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor gets a gRPC interceptor that logs outbound unary calls,
+// with method, status code and latency, at Debug,
+// or with a severity derived from the status code, for errors; see Logger.Status.
+//
+// Entries are correlated with the Context, or with the call's Context if nil.
+// With SetReportCaller, their source location is where the interceptor was created.
+func UnaryClientInterceptor(ctx context.Context) grpc.UnaryClientInterceptor {
+	caller := callerLocation(0)
+	return func(callCtx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := now()
+		err := invoker(callCtx, method, req, reply, cc, opts...)
+		logCall(ctx, callCtx, caller, method, start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor is like UnaryClientInterceptor, for streaming calls.
+// Calls are logged when the stream ends, or fails.
+func StreamClientInterceptor(ctx context.Context) grpc.StreamClientInterceptor {
+	caller := callerLocation(0)
+	return func(callCtx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := now()
+		s, err := streamer(callCtx, desc, cc, method, opts...)
+		if err != nil {
+			logCall(ctx, callCtx, caller, method, start, err)
+			return nil, err
+		}
+		return &loggedStream{ClientStream: s, done: func(err error) {
+			logCall(ctx, callCtx, caller, method, start, err)
+		}}, nil
+	}
+}
+
+type loggedStream struct {
+	grpc.ClientStream
+	once sync.Once
+	done func(error)
+}
+
+func (s *loggedStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && err != io.EOF {
+		s.once.Do(func() { s.done(err) })
+	}
+	return err
+}
+
+func (s *loggedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		s.once.Do(func() { s.done(err) })
+	}
+	return err
+}
+
+// logCall logs a call, with the source location of the interceptor's creation.
+func logCall(ctx, callCtx context.Context, caller *logpb.LogEntrySourceLocation, method string, start time.Time, err error) {
+	if ctx == nil {
+		ctx = callCtx
+	}
+	code := status.Code(err)
+
+	s := logging.Debug
+	if code != codes.OK {
+		s = codeSeverity(code)
+	}
+	fields := map[string]interface{}{
+		"method":     method,
+		"code":       code.String(),
+		"latency_ms": now().Sub(start).Seconds() * 1000,
+	}
+	if err != nil {
+		fields["error"] = err
+	}
+	l := newLogger(ctx, s).withBuiltins(fields)
+	l.caller = caller
+	l.Printf("%s %s", method, code)
+}
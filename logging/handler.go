@@ -0,0 +1,171 @@
+package logging
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+// A HandlerOption configures a Handler.
+type HandlerOption func(*handler)
+
+// WithEchoHeader sets a response header (example: "X-Request-Id")
+// that echoes the request's execution ID, or trace ID,
+// so that users can quote it in support requests.
+func WithEchoHeader(name string) HandlerOption {
+	return func(h *handler) { h.echo = name }
+}
+
+// WithName sets the handler label of entries logged for requests
+// served by the Handler; see WithHandlerName.
+func WithName(name string) HandlerOption {
+	return func(h *handler) { h.name = name }
+}
+
+// Handler wraps an http.Handler, creating a logging Context for each request
+// with ForRequest, and logging an access entry after the request is served.
+//
+// The access entry is the request's Summary. It's logged at Info,
+// Warning for 4xx, and Error for 5xx responses or if errors were recorded,
+// or the highest severity logged for the request, if higher; see MaxSeverity.
+// With SetReportCaller, its source location is where Handler was called.
+func Handler(h http.Handler, opts ...HandlerOption) http.Handler {
+	res := &handler{next: h, caller: callerLocation(0)}
+	for _, o := range opts {
+		o(res)
+	}
+	return res
+}
+
+type handler struct {
+	next   http.Handler
+	echo   string
+	name   string
+	caller *logpb.LogEntrySourceLocation
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := ForRequest(r)
+	if h.name != "" {
+		ctx = WithHandlerName(ctx, h.name)
+	}
+	r = r.WithContext(ctx)
+
+	if h.echo != "" {
+		if id := ExecutionID(ctx); id != "" {
+			w.Header().Set(h.echo, id)
+		}
+	}
+
+	rw := &responseWriter{ResponseWriter: w}
+	var next http.ResponseWriter = rw
+	if _, ok := w.(http.Hijacker); ok {
+		next = hijacker{rw}
+	}
+	h.next.ServeHTTP(next, r)
+
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	s := logging.Info
+	switch {
+	case rw.status >= 500:
+		s = logging.Error
+	case rw.status >= 400:
+		s = logging.Warning
+	}
+
+	req := httpRequest(r)
+	req.Status = rw.status
+	req.ResponseSize = rw.size
+	req.Latency = Elapsed(ctx)
+	Summary(ctx).emit(s, req, h.caller)
+}
+
+// Request gets a Logger that attaches the request's metadata
+// (method, URL, user agent, client IP, etc) to entries,
+// to log a request on demand, without the Handler middleware:
+//
+//	logging.Warning(ctx).Request(r).Print("suspicious request")
+func (l Logger) Request(r *http.Request) Logger {
+	if r != nil && !l.noop {
+		l.req = httpRequest(r)
+	}
+	return l
+}
+
+func httpRequest(r *http.Request) *logging.HTTPRequest {
+	return &logging.HTTPRequest{
+		Request:     r,
+		RequestSize: r.ContentLength,
+		RemoteIP:    clientIP(r),
+	}
+}
+
+// clientIP gets the IP address of the client that issued the request,
+// from the X-Forwarded-For header, if set by the load balancer.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		if i := strings.IndexByte(fwd, ','); i >= 0 {
+			fwd = fwd[:i]
+		}
+		return strings.TrimSpace(fwd)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Unwrap gets the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush forwards to the wrapped writer, for streaming handlers.
+// It does nothing if the wrapped writer isn't an http.Flusher.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// hijacker is a responseWriter whose wrapped writer is an http.Hijacker.
+type hijacker struct{ *responseWriter }
+
+// Hijack forwards to the wrapped writer, for WebSockets and the like.
+func (w hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
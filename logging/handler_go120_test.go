@@ -0,0 +1,31 @@
+//go:build go1.20
+// +build go1.20
+
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_responseController(t *testing.T) {
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+
+	var err error
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute))
+	})))
+	defer srv.Close()
+
+	res, e := http.Get(srv.URL)
+	if e != nil {
+		t.Fatal(e)
+	}
+	res.Body.Close()
+	if err != nil {
+		t.Errorf("SetWriteDeadline: %v", err)
+	}
+}
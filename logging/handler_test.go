@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_flusher(t *testing.T) {
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+
+	var canFlush, canHijack bool
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, canFlush = w.(http.Flusher)
+		_, canHijack = w.(http.Hijacker)
+		w.Write([]byte("chunk"))
+		w.(http.Flusher).Flush()
+	}))
+
+	// A ResponseRecorder can flush, but not hijack.
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if !canFlush || canHijack {
+		t.Errorf("Flusher = %v, Hijacker = %v", canFlush, canHijack)
+	}
+	if !rec.Flushed {
+		t.Error("response not flushed")
+	}
+
+	// A server's writer can do both.
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if !canFlush || !canHijack {
+		t.Errorf("Flusher = %v, Hijacker = %v", canFlush, canHijack)
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/logging"
+)
+
+var (
+	hooksMtx sync.RWMutex
+	hooks    []*hook
+)
+
+type hook struct {
+	fn func(context.Context, logging.Entry)
+}
+
+// AddHook registers a function that receives every entry logged,
+// after filtering, along with the Context it was logged with.
+// It allows forwarding entries to other logging pipelines.
+//
+// Hooks are called synchronously, and must not modify entries.
+func AddHook(hook func(context.Context, logging.Entry)) {
+	addHook(hook)
+}
+
+// addHook returns a function that removes the hook.
+func addHook(fn func(context.Context, logging.Entry)) func() {
+	h := &hook{fn}
+	hooksMtx.Lock()
+	hooks = append(hooks, h)
+	hooksMtx.Unlock()
+
+	return func() {
+		hooksMtx.Lock()
+		defer hooksMtx.Unlock()
+		for i, o := range hooks {
+			if o == h {
+				// Copy, as runHooks may be iterating the old slice.
+				hooks = append(hooks[:i:i], hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func runHooks(ctx context.Context, entry logging.Entry) {
+	hooksMtx.RLock()
+	hs := hooks
+	hooksMtx.RUnlock()
+
+	if len(hs) > 0 && ctx == nil {
+		ctx = context.Background()
+	}
+	for _, h := range hs {
+		h.fn(ctx, entry)
+	}
+}
+
+var (
+	flushHooksMtx sync.Mutex
+	flushHooks    []func() error
+)
+
+// AddFlushHook registers a function called by Flush,
+// so that pipelines fed by hooks (see AddHook) can drain.
+func AddFlushHook(hook func() error) {
+	flushHooksMtx.Lock()
+	flushHooks = append(flushHooks, hook)
+	flushHooksMtx.Unlock()
+}
+
+func runFlushHooks() (err error) {
+	flushHooksMtx.Lock()
+	hs := flushHooks
+	flushHooksMtx.Unlock()
+
+	for _, h := range hs {
+		if e := h(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
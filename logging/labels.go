@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+var (
+	labelsMtx sync.RWMutex
+	labels    map[string]string
+)
+
+type labelsKey struct{}
+
+// SetDefaultLabels sets labels added to every entry.
+//
+// Labels can be set at several levels.
+// When the same key is set at more than one level, the precedence is,
+// from highest to lowest:
+//   - EntryBuilder.Label
+//   - Logger.WithLabels
+//   - WithLabels, inner Contexts over outer ones
+//   - SetDefaultLabels
+//   - labels reserved by this package (execution_id, see SetExecutionIDLabel, revision, project_id, region and seq)
+func SetDefaultLabels(l map[string]string) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setDefaultLabels(l)
+}
+
+func setDefaultLabels(l map[string]string) {
+	l = mergeLabels(l)
+	labelsMtx.Lock()
+	labels = l
+	labelsMtx.Unlock()
+}
+
+func defaultLabels() map[string]string {
+	labelsMtx.RLock()
+	defer labelsMtx.RUnlock()
+	return labels
+}
+
+// WithLabels creates a Context that adds labels to every entry logged with it.
+// Labels accumulate over nested Contexts.
+func WithLabels(ctx context.Context, l map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	outer, _ := ctx.Value(labelsKey{}).(map[string]string)
+	return context.WithValue(ctx, labelsKey{}, mergeLabels(outer, l))
+}
+
+// WithHandlerName creates a Context that adds a handler label to every entry
+// logged with it, for per-handler analysis of functions that serve many.
+// If the Context was created by ForRequest, the label is also added to its Summary.
+func WithHandlerName(ctx context.Context, name string) context.Context {
+	l := map[string]string{"handler": name}
+	if s := Summary(ctx); s != nil {
+		s.mtx.Lock()
+		s.labels = mergeLabels(s.labels, l)
+		s.mtx.Unlock()
+	}
+	return WithLabels(ctx, l)
+}
+
+// WithLabels gets a Logger that adds labels to every entry.
+func (l Logger) WithLabels(labels map[string]string) Logger {
+	if l.noop {
+		return l
+	}
+	l.labels = mergeLabels(l.labels, labels)
+	return l
+}
+
+// mergeLabels merges label maps into a new map, later maps taking precedence.
+// It returns nil if there are no labels.
+func mergeLabels(maps ...map[string]string) map[string]string {
+	var res map[string]string
+	for _, m := range maps {
+		for k, v := range m {
+			if res == nil {
+				res = map[string]string{}
+			}
+			res[k] = v
+		}
+	}
+	return res
+}
+
+type labelOverride struct {
+	key, value string
+	min        logging.Severity
+}
+
+var (
+	overridesMtx sync.RWMutex
+	overrides    []labelOverride
+	hasOverrides int32
+)
+
+// SetLabelLevelOverride sets a different minimum severity
+// for entries that carry the label (example: a tenant under investigation).
+// Entries without the label use the minimum set by SetMinLevel.
+func SetLabelLevelOverride(key, value string, min logging.Severity) {
+	overridesMtx.Lock()
+	defer overridesMtx.Unlock()
+	for i, o := range overrides {
+		if o.key == key && o.value == value {
+			overrides[i].min = min
+			return
+		}
+	}
+	overrides = append(overrides, labelOverride{key, value, min})
+	atomic.StoreInt32(&hasOverrides, 1)
+}
+
+func hasLevelOverrides() bool {
+	return atomic.LoadInt32(&hasOverrides) != 0
+}
+
+// levelOverride reports whether an override for the labels allows the severity.
+func levelOverride(labels map[string]string, s logging.Severity) bool {
+	overridesMtx.RLock()
+	defer overridesMtx.RUnlock()
+	for _, o := range overrides {
+		if v, ok := labels[o.key]; ok && v == o.value && s >= o.min {
+			return true
+		}
+	}
+	return false
+}
+
+var resourceLabels int32
+
+// SetIncludeResourceLabels enables or disables the project_id and region labels,
+// detected from the environment, on every entry,
+// for logs exported to sinks that don't keep the monitored resource.
+func SetIncludeResourceLabels(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&resourceLabels, v)
+}
+
+func addResourceLabels(l map[string]string) {
+	if atomic.LoadInt32(&resourceLabels) == 0 {
+		return
+	}
+	if p := projectID(); p != "" {
+		l["project_id"] = p
+	}
+	clientMtx.RLock()
+	res := resource
+	clientMtx.RUnlock()
+	if r := res.GetLabels()["region"]; r != "" {
+		l["region"] = r
+	} else if r := os.Getenv("FUNCTION_REGION"); r != "" {
+		l["region"] = r
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestLabelPrecedence(t *testing.T) {
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+	defer SetDefaultLabels(nil)
+
+	var got map[string]string
+	defer addHook(func(_ context.Context, e logging.Entry) { got = e.Labels })()
+
+	// Each level sets execution_id, which is also reserved,
+	// so the value that wins tells which level took precedence.
+	const key = "execution_id"
+	tests := []struct {
+		name                           string
+		dflt, ctx, logger, entry, want string
+	}{
+		{name: "reserved", want: "reserved"},
+		{name: "default", dflt: "default", want: "default"},
+		{name: "context", dflt: "default", ctx: "context", want: "context"},
+		{name: "logger", dflt: "default", ctx: "context", logger: "logger", want: "logger"},
+		{name: "entry", dflt: "default", ctx: "context", logger: "logger", entry: "entry", want: "entry"},
+		{name: "context over reserved", ctx: "context", want: "context"},
+		{name: "entry over reserved", entry: "entry", want: "entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Function-Execution-Id", "reserved")
+			ctx := ForRequest(r)
+
+			SetDefaultLabels(nil)
+			if tt.dflt != "" {
+				SetDefaultLabels(map[string]string{key: tt.dflt})
+			}
+			if tt.ctx != "" {
+				ctx = WithLabels(ctx, map[string]string{key: "outer"})
+				ctx = WithLabels(ctx, map[string]string{key: tt.ctx})
+			}
+			l := Info(ctx)
+			if tt.logger != "" {
+				l = l.WithLabels(map[string]string{key: tt.logger})
+			}
+			b := l.Entry().Message("test")
+			if tt.entry != "" {
+				b.Label(key, tt.entry)
+			}
+
+			got = nil
+			b.Emit()
+			if v := got[key]; v != tt.want {
+				t.Errorf("%s = %q, want %q", key, v, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLabels(t *testing.T) {
+	got := mergeLabels(
+		map[string]string{"a": "1", "b": "1", "c": "1"},
+		nil,
+		map[string]string{"b": "2", "c": "2"},
+		map[string]string{"c": "3"},
+	)
+	want := map[string]string{"a": "1", "b": "2", "c": "3"}
+	if len(got) != len(want) {
+		t.Fatalf("mergeLabels() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("mergeLabels()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+	if got := mergeLabels(nil, map[string]string{}); got != nil {
+		t.Errorf("mergeLabels() = %v, want nil", got)
+	}
+}
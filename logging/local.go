@@ -0,0 +1,215 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+var (
+	formatMtx sync.RWMutex
+	format    *template.Template
+	color     bool
+	theme     = DefaultColorTheme()
+	tlayout   string
+	tzone     *time.Location
+)
+
+// A LocalEntry is the data available to the template set by SetFormat.
+type LocalEntry struct {
+	Severity    logging.Severity
+	Time        time.Time
+	Timestamp   string // Time formatted with the layout set by SetTimeFormat, or RFC 3339.
+	Message     string
+	Fields      map[string]interface{}
+	ExecutionID string
+	Labels      map[string]string
+}
+
+// SetFormat sets the text/template, executed with a LocalEntry, that formats local output.
+func SetFormat(tmpl string) error {
+	var t *template.Template
+	var err error
+	if tmpl != "" {
+		t, err = template.New("format").Parse(tmpl)
+	}
+
+	formatMtx.Lock()
+	format = t
+	formatMtx.Unlock()
+	return err
+}
+
+var severityPrefix int32
+
+// SetSeverityPrefix enables or disables prefixing local output with severity (example: "[WARNING] ").
+func SetSeverityPrefix(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&severityPrefix, v)
+}
+
+// SetTimeFormat sets the layout (example: time.StampMicro) of times in local output.
+func SetTimeFormat(layout string) {
+	formatMtx.Lock()
+	tlayout = layout
+	formatMtx.Unlock()
+}
+
+// SetTimeZone sets the time zone of times in local output (default: not converted).
+func SetTimeZone(loc *time.Location) {
+	formatMtx.Lock()
+	tzone = loc
+	formatMtx.Unlock()
+}
+
+// SetColor enables or disables coloring local output by severity.
+func SetColor(enabled bool) {
+	formatMtx.Lock()
+	color = enabled
+	formatMtx.Unlock()
+}
+
+// SetColorTheme sets the ANSI SGR codes (example: "1;31") used to color
+// entries of each severity, when color is enabled.
+// Severities missing from the theme are not colored.
+// A nil theme restores the DefaultColorTheme.
+func SetColorTheme(t map[logging.Severity]string) error {
+	if t == nil {
+		t = DefaultColorTheme()
+	}
+
+	codes := make(map[logging.Severity]string, len(t))
+	for s, c := range t {
+		c = strings.TrimSuffix(strings.TrimPrefix(c, "\x1b["), "m")
+		if strings.Trim(c, "0123456789;") != "" {
+			return fmt.Errorf("logging: invalid color code for %v: %q", s, t[s])
+		}
+		codes[s] = c
+	}
+
+	formatMtx.Lock()
+	theme = codes
+	formatMtx.Unlock()
+	return nil
+}
+
+// DefaultColorTheme returns the default color theme.
+func DefaultColorTheme() map[logging.Severity]string {
+	return map[logging.Severity]string{
+		logging.Debug:     "90",
+		logging.Notice:    "36",
+		logging.Warning:   "33",
+		logging.Error:     "31",
+		logging.Critical:  "1;31",
+		logging.Alert:     "1;35",
+		logging.Emergency: "1;41",
+	}
+}
+
+// A FallbackMode is what happens to entries
+// when there is no logging client; see SetFallback.
+type FallbackMode int32
+
+const (
+	// FallbackStdout writes entries to stdout, or stderr at Error or above.
+	FallbackStdout FallbackMode = iota
+	// FallbackStderr writes all entries to stderr.
+	FallbackStderr
+	// FallbackDiscard silently drops entries.
+	FallbackDiscard
+	// FallbackPanic panics, to fail loudly during development.
+	FallbackPanic
+)
+
+var fallback int32
+
+// SetFallback sets what happens to entries when there is no logging client
+// (default: FallbackStdout).
+// It doesn't affect output redirected with SetOutput.
+func SetFallback(mode FallbackMode) {
+	atomic.StoreInt32(&fallback, int32(mode))
+}
+
+func local(entry logging.Entry) {
+	var w io.Writer = os.Stdout
+	switch FallbackMode(atomic.LoadInt32(&fallback)) {
+	case FallbackDiscard:
+		return
+	case FallbackPanic:
+		msg, _ := unpayload(entry.Payload)
+		panic("logging: no logging client: " + msg)
+	case FallbackStderr:
+		w = os.Stderr
+	default:
+		if entry.Severity >= logging.Error {
+			w = os.Stderr
+		}
+	}
+
+	msg, fields := unpayload(entry.Payload)
+
+	formatMtx.RLock()
+	t, layout, loc := format, tlayout, tzone
+	code := ""
+	if color {
+		code = theme[entry.Severity]
+	}
+	formatMtx.RUnlock()
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = now()
+	}
+	if loc != nil {
+		entry.Timestamp = entry.Timestamp.In(loc)
+	}
+	timestamp := entry.Timestamp.Format(time.RFC3339Nano)
+	if layout != "" {
+		timestamp = entry.Timestamp.Format(layout)
+	}
+
+	if t != nil {
+		var buf bytes.Buffer
+		err := t.Execute(&buf, LocalEntry{
+			Severity:    entry.Severity,
+			Time:        entry.Timestamp,
+			Timestamp:   timestamp,
+			Message:     msg,
+			Fields:      fields,
+			ExecutionID: entry.Labels[executionIDLabel()],
+			Labels:      entry.Labels,
+		})
+		if err == nil {
+			msg = strings.TrimRight(buf.String(), "\n")
+		}
+	} else {
+		if len(fields) > 0 {
+			if buf, err := json.Marshal(fields); err == nil {
+				msg += " " + string(buf)
+			}
+		}
+		if layout != "" {
+			msg = timestamp + " " + msg
+		}
+	}
+
+	if atomic.LoadInt32(&severityPrefix) != 0 {
+		msg = "[" + strings.ToUpper(entry.Severity.String()) + "] " + msg
+	}
+	if code != "" {
+		msg = "\x1b[" + code + "m" + msg + "\x1b[0m"
+	}
+
+	fmt.Fprintln(w, msg)
+}
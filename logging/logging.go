@@ -15,155 +15,1149 @@
 //    GOOGLE_CLOUD_PROJECT:  The current GCP project ID.
 //    FUNCTION_NAME:         The name of the function resource.
 //    FUNCTION_REGION:       The function region (example: us-central1).
+//
+// Optionally, to set the severity of entries logged by Default, set:
+//    DEFAULT_SEVERITY:      A severity name (example: INFO).
+//
+// To test against a Cloud Logging emulator, also set:
+//    LOGGING_EMULATOR_HOST: The emulator address (example: localhost:8080).
+//
+// Without a logging client (example: these variables are unset, or after Close),
+// entries are written as text lines to stdout, or stderr for errors; see SetFallback.
+// By default, each line of this local output is the message, followed by any
+// structured fields as JSON, and prefixed by the time if SetTimeFormat set a layout.
+// SetFormat replaces this with a template (an invalid template restores the default).
+// SetSeverityPrefix, SetTimeZone and SetColor also apply to local output.
 package logging
 
 import (
 	"context"
+	crand "crypto/rand"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"cloud.google.com/go/functions/metadata"
 	"cloud.google.com/go/logging"
 
+	"google.golang.org/api/option"
 	"google.golang.org/genproto/googleapis/api/monitoredres"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
 )
 
 var (
-	once   sync.Once
-	logger *logging.Logger
+	once      sync.Once
+	clientMtx sync.RWMutex
+	client    *logging.Client
+	resource  *monitoredres.MonitoredResource
+	logger    *logging.Logger
+	closed    bool
 )
 
+func projectID() string {
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if project == "" {
+		project = os.Getenv("GCP_PROJECT")
+	}
+	if project == "" {
+		project = os.Getenv("GCLOUD_PROJECT")
+	}
+	return project
+}
+
+// setup creates the logging client, on first use, rather than from init,
+// so entries logged from other packages' init functions can't race it:
+// concurrent callers wait for the client in once.Do.
 func setup() {
 	once.Do(func() {
-		project := os.Getenv("GOOGLE_CLOUD_PROJECT")
-		function := os.Getenv("FUNCTION_NAME")
-		region := os.Getenv("FUNCTION_REGION")
+		clientMtx.Lock()
+		defer clientMtx.Unlock()
 
-		if project == "" {
-			project = os.Getenv("GCP_PROJECT")
+		c, res, err := connect(customResource, endpoint)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to create logging client:", err)
+			return
 		}
-		if project == "" {
-			project = os.Getenv("GCLOUD_PROJECT")
+
+		client, resource = c, res
+		logger = client.Logger(logName, logging.CommonResource(resource))
+	})
+}
+
+func connect(custom *monitoredres.MonitoredResource, endpoint string) (*logging.Client, *monitoredres.MonitoredResource, error) {
+	if projectID() == "" {
+		return nil, nil, errors.New("GOOGLE_CLOUD_PROJECT environment variable unset or missing")
+	}
+	res, err := newResource(custom)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := newClient(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, res, nil
+}
+
+func newResource(custom *monitoredres.MonitoredResource) (*monitoredres.MonitoredResource, error) {
+	if custom != nil {
+		return custom, nil
+	}
+
+	function := os.Getenv("FUNCTION_NAME")
+	region := os.Getenv("FUNCTION_REGION")
+
+	if function == "" {
+		function = os.Getenv("K_SERVICE")
+	}
+	if function == "" {
+		return nil, errors.New("FUNCTION_NAME environment variable unset or missing")
+	}
+
+	if region == "" {
+		return nil, errors.New("FUNCTION_REGION environment variable unset or missing")
+	}
+
+	return &monitoredres.MonitoredResource{
+		Type:   "cloud_function",
+		Labels: map[string]string{"region": region, "function_name": function},
+	}, nil
+}
+
+func newClient(endpoint string) (*logging.Client, error) {
+	var opts []option.ClientOption
+	if host := os.Getenv("LOGGING_EMULATOR_HOST"); host != "" {
+		opts = append(opts,
+			option.WithEndpoint(host),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithInsecure()))
+	} else if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	c, err := logging.NewClient(context.Background(), projectID(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.OnError = onClientError
+	return c, nil
+}
+
+type contextKey struct{}
+
+// drawKey holds the request's sampling draw, in [0, 1),
+// or -1 for requests that are always kept.
+type drawKey struct{}
+type startKey struct{}
+
+// ForRequest creates a logging Context for the Request.
+//
+// Entries are correlated by the execution id, see ExecutionID,
+// and by the trace from the X-Cloud-Trace-Context header.
+//
+// The sampling decision for the request is made once, here,
+// so that either all or none of its entries are kept by the sampler.
+// Requests whose trace was sampled upstream are always kept.
+//
+// Requests from Cloud Tasks are labeled with the attempt number,
+// starting at 1, from the X-CloudTasks-TaskRetryCount header.
+//
+// A nil Request yields a background Context.
+func ForRequest(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	markInit()
+
+	id := r.Header.Get("Function-Execution-Id")
+	if id == "" {
+		id = gatewayRequestID(r)
+	}
+	ctx := withCorrelation(r.Context(), id, r.Header.Get("X-Cloud-Trace-Context"))
+	labels := map[string]string{"trigger": "http"}
+	gatewayLabels(r, labels)
+	if n, err := strconv.Atoi(r.Header.Get("X-CloudTasks-TaskRetryCount")); err == nil && n >= 0 {
+		labels["attempt"] = strconv.Itoa(n + 1)
+	}
+	ctx = WithLabels(ctx, labels)
+	return withSummary(ctx, r)
+}
+
+// Begin creates a logging Context for the Request, with ForRequest,
+// and an Info Logger for it, combining the common setup:
+//
+//	ctx, log := logging.Begin(r)
+//	log.Print("handling request")
+//
+// Entries logged later with the Context share the same correlation.
+// Default labels are added to every entry, as usual.
+func Begin(r *http.Request) (context.Context, Logger) {
+	ctx := ForRequest(r)
+	return ctx, Info(ctx)
+}
+
+// ForContext creates a logging Context for a gRPC invocation,
+// from the incoming gRPC metadata of the Context.
+//
+// If the Context has no incoming gRPC metadata, it is returned unchanged,
+// and entries fall back to the event metadata or the Context from ForRequest.
+//
+// A nil Context yields a background Context.
+func ForContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	md, ok := grpcmd.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	get := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
 		}
-		if project == "" {
-			fmt.Fprintln(os.Stderr, "Failed to create logging client:", "GOOGLE_CLOUD_PROJECT environment variable unset or missing")
-			return
+		return ""
+	}
+	ctx = withCorrelation(ctx,
+		get("function-execution-id"),
+		get("x-cloud-trace-context"))
+	return WithLabels(ctx, map[string]string{"trigger": "grpc"})
+}
+
+// ForEvent creates a logging Context for a background function,
+// from the event metadata of the Context.
+//
+// Entries are labeled with the event ID and type, the trigger, and the triggering resource
+// (example: the bucket and object for Cloud Storage).
+//
+// Without event metadata, a random UUID correlates entries, see ExecutionID,
+// unless the Context already has a correlation id.
+//
+// A nil Context yields a background Context.
+func ForEvent(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	meta, _ := metadata.FromContext(ctx)
+	if meta == nil {
+		if _, ok := ctx.Value(contextKey{}).(string); ok {
+			return ctx
 		}
+		return withCorrelation(ctx, "", "")
+	}
 
-		if function == "" {
-			project = os.Getenv("K_SERVICE")
+	labels := map[string]string{
+		"event_id":   meta.EventID,
+		"event_type": meta.EventType,
+		"trigger":    triggerType(meta.EventType),
+	}
+	if res := meta.Resource; res != nil {
+		if res.RawPath != "" {
+			labels["resource"] = res.RawPath
 		}
-		if function == "" {
-			fmt.Fprintln(os.Stderr, "Failed to create logging client:", "FUNCTION_NAME environment variable unset or missing")
-			return
+		if res.Service != "" {
+			labels["resource_service"] = res.Service
+		}
+		if res.Name != "" {
+			labels["resource_name"] = res.Name
 		}
+		if res.Type != "" {
+			labels["resource_type"] = res.Type
+		}
+	}
 
-		if region == "" {
-			fmt.Fprintln(os.Stderr, "Failed to create logging client:", "FUNCTION_REGION environment variable unset or missing")
-			return
+	ctx = withCorrelation(ctx, meta.EventID, "")
+	return WithLabels(ctx, labels)
+}
+
+var triggerTypes = []struct{ service, trigger string }{
+	{"pubsub", "pubsub"},
+	{"storage", "storage"},
+	{"firestore", "firestore"},
+	{"firebase.database", "firebase_database"},
+	{"firebase.auth", "firebase_auth"},
+	{"firebase.remoteconfig", "firebase_remoteconfig"},
+	{"firebase.analytics", "firebase_analytics"},
+}
+
+// triggerType normalizes an event type
+// (examples: "google.pubsub.topic.publish", "providers/cloud.firestore/eventTypes/document.write")
+// to a trigger label (examples: "pubsub", "firestore").
+func triggerType(eventType string) string {
+	for _, t := range triggerTypes {
+		if strings.Contains(eventType, t.service) {
+			return t.trigger
 		}
+	}
+	return "event"
+}
 
-		ctx := context.Background()
-		client, err := logging.NewClient(ctx, project)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Failed to create logging client:", err)
-			return
+// withCorrelation stores the correlation id on the Context, which is,
+// in order: the execution id, the trace id, or a random UUID.
+func withCorrelation(ctx context.Context, id, trace string) context.Context {
+	ctx = context.WithValue(ctx, startKey{}, now())
+	ctx = context.WithValue(ctx, attachKey{}, &attached{})
+	traceID, spanID, traceSampled := parseTraceContext(trace)
+	if traceID != "" {
+		// The span ID is decimal in the header, but hexadecimal in entries.
+		if n, err := strconv.ParseUint(spanID, 10, 64); err == nil {
+			spanID = fmt.Sprintf("%016x", n)
 		}
+		ctx = context.WithValue(ctx, traceKey{}, traceInfo{traceID, spanID, traceSampled})
+	}
+	if id == "" {
+		id = traceID
+	}
+	if id == "" {
+		id = newUUID()
+	}
+	ctx = context.WithValue(ctx, contextKey{}, id)
+	// Traces sampled upstream are always kept;
+	// for other traces, the draw is a hash of the trace ID.
+	draw := rand.Float64()
+	if traceSampled {
+		draw = -1
+	} else if traceID != "" {
+		draw = traceDraw(traceID)
+	}
+	return context.WithValue(ctx, drawKey{}, draw)
+}
+
+// ForceSampled creates a Context whose entries are marked as trace sampled,
+// regardless of the X-Cloud-Trace-Context header, and kept by the sampler,
+// to correlate logs of unsampled traces during investigations.
+// It does nothing for Contexts without a trace.
+func ForceSampled(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	t, ok := ctx.Value(traceKey{}).(traceInfo)
+	if !ok {
+		return ctx
+	}
+	t.sampled = true
+	ctx = context.WithValue(ctx, traceKey{}, t)
+	return context.WithValue(ctx, drawKey{}, -1.0)
+}
+
+type traceKey struct{}
+
+type traceInfo struct {
+	id      string
+	span    string
+	sampled bool
+}
+
+// resourceName gets the trace resource name: projects/PROJECT_ID/traces/TRACE_ID
+func (t traceInfo) resourceName() string {
+	if project := projectID(); project != "" {
+		return "projects/" + project + "/traces/" + t.id
+	}
+	return t.id
+}
+
+// ExecutionID gets the correlation id of the Context.
+//
+// For HTTP functions, this is the execution id if available,
+// the trace id for runtimes that don't provide one,
+// or a random UUID, if all else fails.
+// For background functions, it's the event ID,
+// or a random UUID, for Contexts without event metadata.
+func ExecutionID(ctx context.Context) string {
+	return newLogger(ctx, logging.Default).id
+}
+
+var extractor atomic.Value
 
-		res := monitoredres.MonitoredResource{
-			Type:   "cloud_function",
-			Labels: map[string]string{"region": region, "function_name": function},
+// SetExecutionIDFromContext sets a function that extracts the execution id
+// from a Context, for interop with other middleware.
+// If it returns an empty string, the built-in sources are used.
+func SetExecutionIDFromContext(extract func(context.Context) string) {
+	if extract == nil {
+		extract = func(context.Context) string { return "" }
+	}
+	extractor.Store(extract)
+}
+
+var idLabel atomic.Value
+
+// SetExecutionIDLabel renames the label that holds the execution id
+// (default: "execution_id"), to fit an existing logging schema.
+func SetExecutionIDLabel(name string) {
+	if name == "" {
+		name = "execution_id"
+	}
+	idLabel.Store(name)
+}
+
+func executionIDLabel() string {
+	if name, ok := idLabel.Load().(string); ok {
+		return name
+	}
+	return "execution_id"
+}
+
+func newUUID() string {
+	var u [16]byte
+	if _, err := crand.Read(u[:]); err != nil {
+		return ""
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// Sampled reports whether the request of a Context from ForRequest or ForContext
+// keeps all its entries, at every severity, under the current sampling rates;
+// see SetSamplingRate and SetSamplingRates.
+// The decision is consistent across instances for traced requests.
+// It reports true if no decision was made.
+func Sampled(ctx context.Context) bool {
+	if ctx != nil {
+		if draw, ok := ctx.Value(drawKey{}).(float64); ok {
+			return draw < lowestSamplingRate()
 		}
+	}
+	return true
+}
 
-		logger = client.Logger("cloudfunctions.googleapis.com/cloud-functions", logging.CommonResource(&res))
-	})
+// Elapsed gets the time elapsed since the Context was created
+// by ForRequest, ForContext or ForEvent.
+// It returns zero if the start time was not recorded.
+func Elapsed(ctx context.Context) time.Duration {
+	if ctx != nil {
+		if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+			return now().Sub(start)
+		}
+	}
+	return 0
 }
 
-type contextKey struct{}
+// WithElapsed gets a Logger that adds the time elapsed since the start of the request,
+// in milliseconds, as the elapsed_ms field; see Elapsed.
+func (l Logger) WithElapsed() Logger {
+	if l.ctx == nil {
+		return l
+	}
+	return l.withBuiltins(map[string]interface{}{
+		"elapsed_ms": Elapsed(l.ctx).Seconds() * 1000,
+	})
+}
 
-// ForRequest creates a logging Context for the Request.
-func ForRequest(r *http.Request) context.Context {
-	ctx := r.Context()
-	id := r.Header.Get("Function-Execution-Id")
-	if id != "" {
-		ctx = context.WithValue(ctx, contextKey{}, id)
+// parseTraceContext parses an X-Cloud-Trace-Context header:
+// TRACE_ID/SPAN_ID;o=TRACE_TRUE
+func parseTraceContext(h string) (trace, span string, sampled bool) {
+	if i := strings.IndexByte(h, ';'); i >= 0 {
+		sampled = h[i+1:] == "o=1"
+		h = h[:i]
 	}
-	return ctx
+	if i := strings.IndexByte(h, '/'); i >= 0 {
+		span = h[i+1:]
+		h = h[:i]
+	}
+	return h, span, sampled
 }
 
 // Flush all loggers. Blocking.
 func Flush() error {
-	if setup(); logger != nil {
-		return logger.Flush()
+	drain()
+	setup()
+	clientMtx.RLock()
+	l := logger
+	clientMtx.RUnlock()
+
+	var err error
+	if l != nil {
+		err = l.Flush()
+		for _, nl := range namedLoggers() {
+			if e := nl.Flush(); err == nil {
+				err = e
+			}
+		}
+	}
+	if e := runFlushHooks(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// FlushContext flushes all loggers, blocking until done or the Context is done.
+func FlushContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- Flush() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var flushOnError, flushing, flushPending int32
+
+// SetFlushOnError enables or disables flushing all loggers
+// after an entry at Error or above is logged,
+// for functions that may be terminated shortly after an error.
+//
+// Flushing happens in the background, and doesn't block the caller,
+// but each flush is a write to the logging API, so smaller batches
+// increase API usage and the latency of concurrent entries.
+// Flushes requested while one is in progress are coalesced.
+func SetFlushOnError(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&flushOnError, v)
+}
+
+func flushAsync() {
+	atomic.StoreInt32(&flushPending, 1)
+	if atomic.CompareAndSwapInt32(&flushing, 0, 1) {
+		go func() {
+			for {
+				for atomic.SwapInt32(&flushPending, 0) != 0 {
+					Flush()
+				}
+				atomic.StoreInt32(&flushing, 0)
+				if atomic.LoadInt32(&flushPending) == 0 ||
+					!atomic.CompareAndSwapInt32(&flushing, 0, 1) {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Close flushes all loggers and closes the logging client. Blocking.
+//
+// After Close, entries are logged to stdout/stderr,
+// and configuration changes don't create a new client.
+// Calling Close more than once is safe.
+func Close() error {
+	drain()
+	setup()
+	clientMtx.Lock()
+	c := client
+	client, logger, closed = nil, nil, true
+	clientMtx.Unlock()
+
+	if c != nil {
+		return c.Close()
 	}
 	return nil
 }
 
+var (
+	samplingMtx   sync.RWMutex
+	samplingRate  = 1.0
+	samplingRates map[logging.Severity]float64
+)
+
+// SetSamplingRate sets the fraction of entries, between 0 and 1, to keep.
+// Entries at Error or above are always kept.
+// It clears the rates set by SetSamplingRates.
+//
+// Entries logged with a Context from ForRequest share a single decision.
+// For traced requests, the decision is a hash of the trace ID,
+// so all instances handling a trace make the same decision.
+func SetSamplingRate(rate float64) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setSamplingRate(rate)
+}
+
+func setSamplingRate(rate float64) {
+	samplingMtx.Lock()
+	samplingRate, samplingRates = rate, nil
+	samplingMtx.Unlock()
+}
+
+// SetSamplingRates sets the fraction of entries, between 0 and 1, to keep
+// for each severity (example: 0.01 for Debug, 0.1 for Info),
+// overriding SetSamplingRate. Severities missing from the map are always kept,
+// and entries at Error or above are always kept. A nil map restores SetSamplingRate.
+//
+// Entries logged with a Context from ForRequest share a single draw,
+// so a request that keeps its Debug entries also keeps its Info entries,
+// and traces sampled upstream keep everything.
+func SetSamplingRates(rates map[logging.Severity]float64) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setSamplingRates(rates)
+}
+
+func setSamplingRates(rates map[logging.Severity]float64) {
+	if rates != nil {
+		m := make(map[logging.Severity]float64, len(rates))
+		for s, r := range rates {
+			m[s] = r
+		}
+		rates = m
+	}
+	samplingMtx.Lock()
+	samplingRates = rates
+	samplingMtx.Unlock()
+}
+
+// samplingRateFor gets the rate for the severity.
+func samplingRateFor(s logging.Severity) float64 {
+	samplingMtx.RLock()
+	defer samplingMtx.RUnlock()
+	if samplingRates != nil {
+		if r, ok := samplingRates[s]; ok {
+			return r
+		}
+		return 1
+	}
+	return samplingRate
+}
+
+// lowestSamplingRate gets the lowest rate of any severity below Error.
+func lowestSamplingRate() float64 {
+	samplingMtx.RLock()
+	defer samplingMtx.RUnlock()
+	if samplingRates == nil {
+		return samplingRate
+	}
+	min := 1.0
+	for s, r := range samplingRates {
+		if s < logging.Error && r < min {
+			min = r
+		}
+	}
+	return min
+}
+
+// keep is the sampling decision for the Logger's entries.
+func (l Logger) keep() bool {
+	rate := samplingRateFor(l.s)
+	if rate >= 1 {
+		return true
+	}
+	if l.drawn {
+		return l.draw < rate
+	}
+	return rand.Float64() < rate
+}
+
+// traceDraw is a deterministic draw, in [0, 1), for the trace.
+func traceDraw(trace string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(trace))
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+var minLevel int32
+
+// SetMinLevel sets the minimum severity of entries to log.
+// Entries below it are discarded.
+func SetMinLevel(s logging.Severity) {
+	configMtx.Lock()
+	defer configMtx.Unlock()
+	setMinLevel(s)
+}
+
+func setMinLevel(s logging.Severity) {
+	atomic.StoreInt32(&minLevel, int32(s))
+}
+
+var (
+	processStart = time.Now()
+	startupMtx   sync.RWMutex
+	startupLevel logging.Severity
+	startupUntil time.Time
+	startup      int32
+)
+
+// SetStartupLevel sets the minimum severity of entries to log during
+// the first duration after process start, instead of the level set by SetMinLevel.
+// It allows capturing verbose cold start logs.
+func SetStartupLevel(s logging.Severity, duration time.Duration) {
+	startupMtx.Lock()
+	startupLevel = s
+	startupUntil = processStart.Add(duration)
+	atomic.StoreInt32(&startup, 1)
+	startupMtx.Unlock()
+}
+
+func globalMin() logging.Severity {
+	s := logging.Severity(atomic.LoadInt32(&minLevel))
+	if atomic.LoadInt32(&startup) != 0 {
+		startupMtx.RLock()
+		ss, until := startupLevel, startupUntil
+		startupMtx.RUnlock()
+		if now().Before(until) {
+			s = ss
+		} else {
+			startupMtx.Lock()
+			if !now().Before(startupUntil) {
+				atomic.StoreInt32(&startup, 0)
+			}
+			startupMtx.Unlock()
+		}
+	}
+	if s < logging.Warning && inQuietWindow() {
+		s = logging.Warning
+	}
+	return s
+}
+
+var (
+	quietMtx   sync.RWMutex
+	quietStart time.Duration
+	quietEnd   time.Duration
+	quiet      int32
+)
+
+// SetQuietWindow sets a daily window, as offsets from midnight
+// in the clock's time zone (see SetClock), during which
+// the minimum severity of entries to log is raised to Warning.
+// The window may span midnight; if start equals end, it's disabled.
+//
+//	logging.SetQuietWindow(2*time.Hour, 4*time.Hour) // 02:00 to 04:00
+func SetQuietWindow(start, end time.Duration) {
+	quietMtx.Lock()
+	quietStart, quietEnd = start, end
+	if start != end {
+		atomic.StoreInt32(&quiet, 1)
+	} else {
+		atomic.StoreInt32(&quiet, 0)
+	}
+	quietMtx.Unlock()
+}
+
+func inQuietWindow() bool {
+	if atomic.LoadInt32(&quiet) == 0 {
+		return false
+	}
+	quietMtx.RLock()
+	start, end := quietStart, quietEnd
+	quietMtx.RUnlock()
+
+	t := now()
+	h, m, s := t.Clock()
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+	if start < end {
+		return start <= d && d < end
+	}
+	return start <= d || d < end
+}
+
+var resolver atomic.Value
+
+// SetLevelResolver sets a function that resolves the minimum severity
+// of entries logged with a Context (example: from a feature flag for the user),
+// overriding the level set by SetMinLevel, when it returns true.
+//
+// It's called for every entry that isn't filtered by WithMinSeverity,
+// so it should be cheap, caching its results if needed.
+// A nil function removes the resolver.
+func SetLevelResolver(fn func(ctx context.Context) (logging.Severity, bool)) {
+	if fn == nil {
+		fn = func(context.Context) (logging.Severity, bool) { return 0, false }
+	}
+	resolver.Store(fn)
+}
+
+func resolveLevel(ctx context.Context) (logging.Severity, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	if fn, ok := resolver.Load().(func(context.Context) (logging.Severity, bool)); ok {
+		return fn(ctx)
+	}
+	return 0, false
+}
+
+type minSeverityKey struct{}
+
+// WithMinSeverity creates a Context that discards entries below the severity,
+// in addition to those discarded by SetMinLevel.
+// It's useful to quiet down a noisy region of code.
+func WithMinSeverity(ctx context.Context, s logging.Severity) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, minSeverityKey{}, s)
+}
+
+var deadlineField int32
+
+// SetDeadlineField enables or disables the deadline_remaining_ms field:
+// the time, in milliseconds, until the Context's deadline, if it has one.
+// It gives visibility on where the latency budget of a request is spent.
+func SetDeadlineField(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&deadlineField, v)
+}
+
+var (
+	sequence      uint64
+	sequenceLabel int32
+)
+
+// SetSequenceLabel enables or disables the "seq" label:
+// a process-wide, monotonically increasing, entry sequence number.
+// It allows ordering entries that share the same timestamp.
+func SetSequenceLabel(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&sequenceLabel, v)
+}
+
+var highest int32
+
+// HighestSeverity gets the highest severity logged by the process.
+// It's useful for a CLI wrapper to exit with an error status
+// if anything at Error or above was logged.
+func HighestSeverity() logging.Severity {
+	return logging.Severity(atomic.LoadInt32(&highest))
+}
+
+func trackSeverity(max *int32, s logging.Severity) {
+	for {
+		old := atomic.LoadInt32(max)
+		if int32(s) <= old || atomic.CompareAndSwapInt32(max, old, int32(s)) {
+			return
+		}
+	}
+}
+
 // A Logger represents an contextualized logging object that pushes entries to Stackdriver.
 type Logger struct {
-	s  logging.Severity
-	id string
+	ctx context.Context
+	s   logging.Severity
+	min logging.Severity
+	id  string
+	nl  *NamedLogger
+
+	labels    map[string]string
+	ctxLabels map[string]string
+	fields    map[string]interface{}
+	ctxFields map[string]interface{}
+	builtins  map[string]interface{} // added by this package
+	trace     traceInfo
+	req       *logging.HTTPRequest
+	res       *monitoredres.MonitoredResource
+
+	draw   float64
+	drawn  bool
+	noop   bool
+	hasErr bool
+	skip   int
+	caller *logpb.LogEntrySourceLocation // set by wrappers
+
+	memStats bool
+	attached *attached
 }
 
 func (l Logger) log(s string) {
-	s = strings.TrimRight(s, "\n")
+	l.logWith(s, nil)
+}
+
+// logWith logs, with edit making final changes to the entry.
+func (l Logger) logWith(s string, edit func(*logging.Entry)) {
+	if l.noop {
+		return
+	}
+	if l.hasErr && l.s < logging.Error && atomic.LoadInt32(&promoteOnError) != 0 {
+		l.s = logging.Error
+	}
+	if l.s < l.min {
+		return
+	}
+	min, ok := resolveLevel(l.ctx)
+	if !ok {
+		min = globalMin()
+	}
+	belowMin := l.s < min
+	if belowMin && !hasLevelOverrides() {
+		return
+	}
+
+	reserved := map[string]string{}
+	if l.id != "" {
+		reserved[executionIDLabel()] = l.id
+	}
+	if atomic.LoadInt32(&revisionLabel) != 0 {
+		if rev := getBuildInfo().revision; rev != "" {
+			reserved["revision"] = rev
+		}
+	}
+	addResourceLabels(reserved)
+
+	entry := logging.Entry{
+		Timestamp: now(),
+		Severity:  l.s,
+		Labels:    mergeLabels(reserved, defaultLabels(), l.ctxLabels, l.labels),
+
+		HTTPRequest: l.req,
+		Resource:    l.res,
+	}
+	if l.trace.id != "" {
+		entry.Trace = l.trace.resourceName()
+		entry.SpanID = l.trace.span
+		entry.TraceSampled = l.trace.sampled
+	}
+	if edit != nil {
+		edit(&entry)
+	}
+
+	if belowMin && !levelOverride(entry.Labels, l.s) {
+		return
+	}
+	if l.s < logging.Error {
+		if !l.keep() {
+			return
+		}
+	}
 
-	if setup(); logger != nil {
-		entry := logging.Entry{
-			Severity: l.s,
-			Payload:  s,
+	if entry.SourceLocation == nil && reportingCaller() {
+		if l.caller != nil {
+			entry.SourceLocation = l.caller
+		} else {
+			entry.SourceLocation = callerLocation(l.skip)
 		}
+	}
+
+	trackSeverity(&highest, l.s)
+	if sum := Summary(l.ctx); sum != nil {
+		trackSeverity(&sum.max, l.s)
+	}
+	entry.Payload = payload(strings.TrimRight(s, "\n"), l.emitFields())
 
-		if l.id != "" {
-			entry.Labels = map[string]string{"execution_id": l.id}
+	if atomic.LoadInt32(&sequenceLabel) != 0 {
+		if _, ok := entry.Labels["seq"]; !ok {
+			if entry.Labels == nil {
+				entry.Labels = map[string]string{}
+			}
+			entry.Labels["seq"] = strconv.FormatUint(atomic.AddUint64(&sequence, 1), 10)
 		}
+	}
 
-		logger.Log(entry)
+	runHooks(l.ctx, entry)
+	if !enqueue(l.nl, entry) {
+		emit(l.nl, entry)
+	}
+	runTees(l.nl, entry)
+	if l.s >= logging.Error && atomic.LoadInt32(&flushOnError) != 0 {
+		flushAsync()
+	}
+}
+
+func emit(nl *NamedLogger, entry logging.Entry) {
+	if writeOutput(entry) {
 		return
 	}
 
-	if l.s >= logging.Error {
-		fmt.Fprintln(os.Stderr, s)
-	} else {
-		fmt.Println(s)
+	setup()
+	clientMtx.RLock()
+	target := logger
+	if nl != nil {
+		target = nl.getLocked()
+	}
+	if target != nil && !breakerAllow() {
+		target = nil
+	}
+	if target != nil {
+		target.Log(entry)
+	}
+	clientMtx.RUnlock()
+
+	if target == nil {
+		local(entry)
 	}
 }
 
+// emitFields gets the structured fields computed at emit time.
+func (l Logger) emitFields() map[string]interface{} {
+	fields := l.fields
+	if len(l.ctxFields) > 0 {
+		fields = addFields(l.ctxFields, l.fields)
+	}
+	if l.s >= logging.Error && l.attached != nil {
+		if a := l.attached.get(); len(a) > 0 {
+			fields = addFields(a, fields)
+		}
+	}
+	fields = applySchema(fields)
+
+	// Fields added by this package aren't subject to the schema.
+	if len(l.builtins) > 0 {
+		fields = addFields(fields, l.builtins)
+	}
+	fields = initFields(fields)
+	if l.memStats {
+		fields = addFields(fields, memStatsFields())
+	}
+	fields = redactFields(fields)
+	if atomic.LoadInt32(&deadlineField) != 0 && l.ctx != nil {
+		if deadline, ok := l.ctx.Deadline(); ok {
+			fields = addFields(fields, map[string]interface{}{
+				"deadline_remaining_ms": deadline.Sub(now()).Seconds() * 1000,
+			})
+		}
+	}
+	return fields
+}
+
 // Print logs using the default formats for its operands.
 // Spaces are added between operands when neither is a string.
 func (l Logger) Print(v ...interface{}) {
+	if l.noop {
+		return
+	}
 	l.log(fmt.Sprint(v...))
 }
 
 // Println logs using the default formats for its operands.
 // Spaces are always added between operands and a newline is appended.
 func (l Logger) Println(v ...interface{}) {
+	if l.noop {
+		return
+	}
 	l.log(fmt.Sprintln(v...))
 }
 
 // Printf logs according to a format specifier.
 func (l Logger) Printf(format string, v ...interface{}) {
+	if l.noop {
+		return
+	}
 	l.log(fmt.Sprintf(format, v...))
 }
 
+// WithResource gets a Logger that overrides the monitored resource of entries,
+// which otherwise is the common cloud_function resource.
+func (l Logger) WithResource(res *monitoredres.MonitoredResource) Logger {
+	l.res = res
+	return l
+}
+
+// If returns the Logger if the condition holds, and a no-op Logger otherwise:
+//
+//	logging.Debug(ctx).If(tracing).Printf(...)
+func (l Logger) If(cond bool) Logger {
+	if !cond {
+		return Logger{noop: true}
+	}
+	return l
+}
+
+var onceKeys sync.Map
+
+// Once logs using the default formats for its operands,
+// only the first time the key is seen by the process.
+// It's meant for one time warnings, like deprecation notices.
+//
+// Keys are never forgotten, so they should be drawn from a small set.
+func (l Logger) Once(key string, v ...interface{}) {
+	if l.noop {
+		return
+	}
+	if _, loaded := onceKeys.LoadOrStore(key, struct{}{}); !loaded {
+		l.log(fmt.Sprint(v...))
+	}
+}
+
+// WrapErr logs a non-nil error and returns it unchanged:
+//
+//	return logging.Error(ctx).WrapErr(err)
+func (l Logger) WrapErr(err error) error {
+	if err != nil && !l.noop {
+		l.log(err.Error())
+	}
+	return err
+}
+
 func newLogger(ctx context.Context, s logging.Severity) Logger {
-	l := Logger{s: s}
+	if ctx == nil {
+		ctx = boundContext()
+	}
+	l := Logger{ctx: ctx, s: s}
 	if ctx != nil {
-		if meta, _ := metadata.FromContext(ctx); meta != nil {
-			l.id = meta.EventID
-		} else {
-			l.id, _ = ctx.Value(contextKey{}).(string)
+		if extract, ok := extractor.Load().(func(context.Context) string); ok {
+			l.id = extract(ctx)
+		}
+		if l.id == "" {
+			if meta, _ := metadata.FromContext(ctx); meta != nil {
+				l.id = meta.EventID
+			} else {
+				l.id, _ = ctx.Value(contextKey{}).(string)
+			}
 		}
+		l.draw, l.drawn = ctx.Value(drawKey{}).(float64)
+		l.min, _ = ctx.Value(minSeverityKey{}).(logging.Severity)
+		l.ctxLabels, _ = ctx.Value(labelsKey{}).(map[string]string)
+		l.ctxFields, _ = ctx.Value(fieldsKey{}).(map[string]interface{})
+		l.trace, _ = ctx.Value(traceKey{}).(traceInfo)
+		l.attached, _ = ctx.Value(attachKey{}).(*attached)
 	}
 	return l
 }
 
-// Default gets a Logger with no assigned severity level.
+// Logf logs with the severity, according to a format specifier:
+// it's shorthand for building a Logger and calling Printf.
+// Fields of the Context are included, and entries are filtered as usual.
+func Logf(ctx context.Context, s logging.Severity, format string, args ...interface{}) {
+	newLogger(ctx, s).Printf(format, args...)
+}
+
+// LogKV logs the message with the severity, and alternating keys and values
+// as structured fields, in addition to those of the Context:
+//
+//	logging.LogKV(ctx, logging.Info, "done", "items", n, "took", d)
+//
+// Keys that aren't strings are formatted; a missing last value is nil.
+// Entries are filtered as usual.
+func LogKV(ctx context.Context, s logging.Severity, msg string, kv ...interface{}) {
+	l := newLogger(ctx, s)
+	if len(kv) > 0 {
+		fields := make(map[string]interface{}, (len(kv)+1)/2)
+		for i := 0; i < len(kv); i += 2 {
+			var v interface{}
+			if i+1 < len(kv) {
+				v = kv[i+1]
+			}
+			fields[fmt.Sprint(kv[i])] = v
+		}
+		l = l.WithFields(fields)
+	}
+	l.log(msg)
+}
+
+// Discard gets a Logger that does nothing: its methods don't format,
+// allocate or log, skipping even the severity checks.
+// It's meant for benchmarks, and for opting out of logging in hot paths,
+// swapping it for another constructor.
+func Discard(ctx context.Context) Logger {
+	return Logger{noop: true}
+}
+
+// Default gets a Logger with no assigned severity level,
+// unless changed with SetDefaultSeverity.
 func Default(ctx context.Context) Logger {
-	return newLogger(ctx, logging.Default)
+	return newLogger(ctx, logging.Severity(atomic.LoadInt32(&defaultSeverity)))
 }
 
 // Debug gets a Logger for debug or trace information.
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestSampled_samplingRates(t *testing.T) {
+	defer SetSamplingRates(nil)
+
+	ctx := ForRequest(httptest.NewRequest("GET", "/", nil))
+	if !Sampled(ctx) {
+		t.Fatal("Sampled() = false, with no sampling")
+	}
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Debug: 0})
+	if Sampled(ctx) {
+		t.Error("Sampled() = true, with Debug entries dropped")
+	}
+	if Debug(ctx).keep() {
+		t.Error("Debug entries kept, at rate 0")
+	}
+	if !Info(ctx).keep() {
+		t.Error("Info entries dropped, with no rate")
+	}
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Error: 0})
+	if !Sampled(ctx) {
+		t.Error("Sampled() = false, with Error entries always kept")
+	}
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Debug: 0})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b12000100000/1;o=0")
+	if ctx := ForceSampled(ForRequest(r)); !Sampled(ctx) || !Debug(ctx).keep() {
+		t.Error("entries dropped, for ForceSampled")
+	}
+}
+
+func TestSamplingRates_reset(t *testing.T) {
+	defer SetSamplingRate(1)
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Debug: 0})
+	SetSamplingRate(0.5)
+	if r := samplingRateFor(logging.Debug); r != 0.5 {
+		t.Errorf("SetSamplingRate: Debug rate = %v, want 0.5", r)
+	}
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Debug: 0})
+	if err := LoadConfig(strings.NewReader(`{"sampling_rate": 0.25}`)); err != nil {
+		t.Fatal(err)
+	}
+	if r := samplingRateFor(logging.Debug); r != 0.25 {
+		t.Errorf("LoadConfig: Debug rate = %v, want 0.25", r)
+	}
+
+	SetSamplingRates(map[logging.Severity]float64{logging.Debug: 0})
+	Configure(Options{SamplingRate: 0.75})
+	if r := samplingRateFor(logging.Debug); r != 0.75 {
+		t.Errorf("Configure: Debug rate = %v, want 0.75", r)
+	}
+	Configure(Options{SamplingRates: map[logging.Severity]float64{logging.Debug: 0}})
+	if r := samplingRateFor(logging.Debug); r != 0 {
+		t.Errorf("Configure: Debug rate = %v, want 0", r)
+	}
+}
@@ -0,0 +1,72 @@
+// Package loggingtest captures entries logged by package logging, for tests.
+//
+// Call Reset at the start of each test, then assert on what was logged:
+//
+//	func TestHandler(t *testing.T) {
+//		loggingtest.Reset()
+//		handler(w, r)
+//		loggingtest.AssertMaxSeverity(t, logging.Warning)
+//	}
+//
+// Captured entries are shared by the process, so tests that assert on them
+// shouldn't run in parallel.
+package loggingtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	gcf "github.com/ncruces/go-gcf/logging"
+)
+
+var (
+	once    sync.Once
+	mtx     sync.Mutex
+	entries []logging.Entry
+	max     = logging.Default
+)
+
+// Reset starts capturing entries, discarding those captured so far.
+func Reset() {
+	once.Do(func() { gcf.AddHook(capture) })
+	mtx.Lock()
+	entries = nil
+	max = logging.Default
+	mtx.Unlock()
+}
+
+// Entries gets the entries captured since Reset.
+func Entries() []logging.Entry {
+	mtx.Lock()
+	defer mtx.Unlock()
+	return append([]logging.Entry(nil), entries...)
+}
+
+// MaxSeverity gets the highest severity captured since Reset.
+func MaxSeverity() logging.Severity {
+	mtx.Lock()
+	defer mtx.Unlock()
+	return max
+}
+
+// AssertMaxSeverity fails the test if any entry captured since Reset
+// has a severity above max, reporting those entries.
+func AssertMaxSeverity(t testing.TB, max logging.Severity) {
+	t.Helper()
+	for _, e := range Entries() {
+		if e.Severity > max {
+			t.Errorf("loggingtest: entry at %v, above %v: %v", e.Severity, max, e.Payload)
+		}
+	}
+}
+
+func capture(_ context.Context, e logging.Entry) {
+	mtx.Lock()
+	entries = append(entries, e)
+	if e.Severity > max {
+		max = e.Severity
+	}
+	mtx.Unlock()
+}
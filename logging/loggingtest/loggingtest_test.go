@@ -0,0 +1,80 @@
+package loggingtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	gcf "github.com/ncruces/go-gcf/logging"
+	"github.com/ncruces/go-gcf/logging/loggingtest"
+)
+
+// fakeTB records failures, instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// message gets the message of an entry, which may have structured fields
+// (example: the init_duration_ms field of the first entry).
+func message(e logging.Entry) interface{} {
+	if m, ok := e.Payload.(map[string]interface{}); ok {
+		return m[gcf.MessageKey()]
+	}
+	return e.Payload
+}
+
+func TestCapture(t *testing.T) {
+	gcf.SetFallback(gcf.FallbackDiscard)
+	defer gcf.SetFallback(gcf.FallbackStdout)
+
+	loggingtest.Reset()
+	gcf.Info(nil).Print("first")
+	gcf.Warning(nil).Print("second")
+
+	entries := loggingtest.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("captured %d entries, want 2", len(entries))
+	}
+	if m0, m1 := message(entries[0]), message(entries[1]); m0 != "first" || m1 != "second" {
+		t.Errorf("captured %v, %v", m0, m1)
+	}
+	if s := loggingtest.MaxSeverity(); s != logging.Warning {
+		t.Errorf("MaxSeverity() = %v, want Warning", s)
+	}
+
+	loggingtest.Reset()
+	if n := len(loggingtest.Entries()); n != 0 {
+		t.Errorf("captured %d entries after Reset", n)
+	}
+	if s := loggingtest.MaxSeverity(); s != logging.Default {
+		t.Errorf("MaxSeverity() = %v after Reset, want Default", s)
+	}
+}
+
+func TestAssertMaxSeverity(t *testing.T) {
+	gcf.SetFallback(gcf.FallbackDiscard)
+	defer gcf.SetFallback(gcf.FallbackStdout)
+
+	loggingtest.Reset()
+	gcf.Info(nil).Print("fine")
+	gcf.Error(nil).Print("bad")
+
+	pass := &fakeTB{}
+	loggingtest.AssertMaxSeverity(pass, logging.Error)
+	if len(pass.errors) != 0 {
+		t.Errorf("failed at Error: %v", pass.errors)
+	}
+
+	fail := &fakeTB{}
+	loggingtest.AssertMaxSeverity(fail, logging.Warning)
+	if len(fail.errors) != 1 {
+		t.Errorf("got %d failures at Warning, want 1: %v", len(fail.errors), fail.errors)
+	}
+}
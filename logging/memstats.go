@@ -0,0 +1,25 @@
+package logging
+
+import "runtime"
+
+// WithMemStats gets a Logger that adds a snapshot of memory statistics
+// as the heap_alloc, heap_objects, num_gc and goroutines fields.
+//
+// Statistics are read when an entry is logged, not where WithMemStats is called,
+// and only for entries that aren't filtered.
+// Reading them briefly stops the world, so don't use it on every entry.
+func (l Logger) WithMemStats() Logger {
+	l.memStats = true
+	return l
+}
+
+func memStatsFields() map[string]interface{} {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return map[string]interface{}{
+		"heap_alloc":   m.HeapAlloc,
+		"heap_objects": m.HeapObjects,
+		"num_gc":       m.NumGC,
+		"goroutines":   runtime.NumGoroutine(),
+	}
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/logging"
+)
+
+var (
+	namedMtx sync.Mutex
+	named    []*NamedLogger
+)
+
+// A NamedLogger pushes entries to a log other than the default one.
+type NamedLogger struct {
+	id     string
+	once   sync.Once
+	logger *logging.Logger
+}
+
+// NewNamedLogger creates a NamedLogger for the log ID (example: "audit").
+func NewNamedLogger(logID string) *NamedLogger {
+	nl := &NamedLogger{id: logID}
+	namedMtx.Lock()
+	named = append(named, nl)
+	namedMtx.Unlock()
+	return nl
+}
+
+func namedLoggers() []*NamedLogger {
+	namedMtx.Lock()
+	defer namedMtx.Unlock()
+	return named
+}
+
+func (nl *NamedLogger) get() *logging.Logger {
+	setup()
+	clientMtx.RLock()
+	defer clientMtx.RUnlock()
+	return nl.getLocked()
+}
+
+// getLocked must be called with clientMtx held.
+func (nl *NamedLogger) getLocked() *logging.Logger {
+	if client == nil {
+		return nil
+	}
+	nl.once.Do(func() {
+		nl.logger = client.Logger(nl.id, logging.CommonResource(resource))
+	})
+	return nl.logger
+}
+
+// Logger gets a Logger with the given severity that pushes entries to this log.
+func (nl *NamedLogger) Logger(ctx context.Context, s logging.Severity) Logger {
+	l := newLogger(ctx, s)
+	l.nl = nl
+	return l
+}
+
+// Flush this logger. Blocking.
+func (nl *NamedLogger) Flush() error {
+	if logger := nl.get(); logger != nil {
+		return logger.Flush()
+	}
+	return nil
+}
+
+// FlushContext flushes this logger, blocking until done or the Context is done.
+func (nl *NamedLogger) FlushContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- nl.Flush() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reset discards the underlying logger, returning it.
+// It must be called with clientMtx held for writing.
+func (nl *NamedLogger) reset() *logging.Logger {
+	old := nl.logger
+	nl.once = sync.Once{}
+	nl.logger = nil
+	return old
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNilContext(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func() context.Context
+	}{
+		{"ForRequest", func() context.Context { return ForRequest(nil) }},
+		{"Begin", func() context.Context { ctx, _ := Begin(nil); return ctx }},
+		{"ForContext", func() context.Context { return ForContext(nil) }},
+		{"ForEvent", func() context.Context { return ForEvent(nil) }},
+		{"ForceSampled", func() context.Context { return ForceSampled(nil) }},
+		{"WithLabels", func() context.Context { return WithLabels(nil, map[string]string{"k": "v"}) }},
+		{"WithHandlerName", func() context.Context { return WithHandlerName(nil, "handler") }},
+		{"WithFieldsContext", func() context.Context { return WithFieldsContext(nil, map[string]interface{}{"k": "v"}) }},
+		{"CopyCorrelation", func() context.Context { return CopyCorrelation(nil, context.Background()) }},
+		{"Span", func() context.Context { ctx, end := Info(nil).Span(nil, "span"); end(); return ctx }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ctx := tt.fn(); ctx == nil {
+				t.Errorf("%s returned a nil Context", tt.name)
+			}
+		})
+	}
+}
+
+func TestNilContext_accessors(t *testing.T) {
+	if id := ExecutionID(nil); id != "" {
+		t.Errorf("ExecutionID() = %q", id)
+	}
+	if !Sampled(nil) {
+		t.Error("Sampled() = false")
+	}
+	if d := Elapsed(nil); d != 0 {
+		t.Errorf("Elapsed() = %v", d)
+	}
+	if s := Summary(nil); s != nil {
+		t.Errorf("Summary() = %v", s)
+	}
+	Attach(nil, "k", "v")
+}
@@ -0,0 +1,102 @@
+// Package otellog bridges logging to the OpenTelemetry logs pipeline.
+//
+// It's a separate module, to keep the OpenTelemetry dependency
+// out of the logging package.
+//
+// Usage:
+//
+//	otellog.Bridge(global.GetLoggerProvider())
+package otellog
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+
+	gcf "github.com/ncruces/go-gcf/logging"
+)
+
+const name = "github.com/ncruces/go-gcf/logging"
+
+// Bridge forwards every entry logged to the LoggerProvider,
+// in addition to Cloud Logging,
+// preserving severity, message, labels and structured fields.
+func Bridge(provider log.LoggerProvider) {
+	logger := provider.Logger(name)
+	gcf.AddHook(func(ctx context.Context, entry logging.Entry) {
+		logger.Emit(ctx, record(entry))
+	})
+}
+
+func record(entry logging.Entry) log.Record {
+	var r log.Record
+	r.SetTimestamp(entry.Timestamp)
+	r.SetSeverity(severity(entry.Severity))
+	r.SetSeverityText(entry.Severity.String())
+
+	switch p := entry.Payload.(type) {
+	case map[string]interface{}:
+		key := gcf.MessageKey()
+		for k, v := range p {
+			if k == key {
+				r.SetBody(attribute.StringValue(fmt.Sprint(v)))
+			} else {
+				r.AddAttributes(attr(k, v))
+			}
+		}
+	default:
+		r.SetBody(attribute.StringValue(fmt.Sprint(p)))
+	}
+
+	for k, v := range entry.Labels {
+		r.AddAttributes(attribute.String(k, v))
+	}
+	if entry.Trace != "" {
+		r.AddAttributes(attribute.String("trace", entry.Trace))
+	}
+	if entry.SpanID != "" {
+		r.AddAttributes(attribute.String("span_id", entry.SpanID))
+	}
+	return r
+}
+
+func attr(k string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(k, v)
+	case bool:
+		return attribute.Bool(k, v)
+	case int:
+		return attribute.Int(k, v)
+	case int64:
+		return attribute.Int64(k, v)
+	case float64:
+		return attribute.Float64(k, v)
+	}
+	return attribute.String(k, fmt.Sprint(v))
+}
+
+func severity(s logging.Severity) log.Severity {
+	switch {
+	case s >= logging.Emergency:
+		return log.SeverityFatal4
+	case s >= logging.Alert:
+		return log.SeverityFatal2
+	case s >= logging.Critical:
+		return log.SeverityFatal1
+	case s >= logging.Error:
+		return log.SeverityError1
+	case s >= logging.Warning:
+		return log.SeverityWarn1
+	case s >= logging.Notice:
+		return log.SeverityInfo2
+	case s >= logging.Info:
+		return log.SeverityInfo1
+	case s >= logging.Debug:
+		return log.SeverityDebug1
+	}
+	return log.SeverityUndefined
+}
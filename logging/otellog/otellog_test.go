@@ -0,0 +1,29 @@
+package otellog
+
+import (
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"go.opentelemetry.io/otel/attribute"
+
+	gcf "github.com/ncruces/go-gcf/logging"
+)
+
+func TestRecord_messageKey(t *testing.T) {
+	gcf.SetMessageKey("msg")
+	defer gcf.SetMessageKey("")
+
+	r := record(logging.Entry{
+		Severity: logging.Info,
+		Payload:  map[string]interface{}{"msg": "hello", "user": "bob"},
+	})
+	if got := r.Body().AsString(); got != "hello" {
+		t.Errorf("body = %q, want %q", got, "hello")
+	}
+	r.WalkAttributes(func(kv attribute.KeyValue) bool {
+		if kv.Key == "msg" {
+			t.Error("message added as an attribute")
+		}
+		return true
+	})
+}
@@ -0,0 +1,242 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+var (
+	outputMtx sync.Mutex
+	output    io.Writer
+	marshal   = json.Marshal
+)
+
+// SetOutput redirects entries to the writer, as JSON lines,
+// bypassing the logging client.
+// The format is that recognized by the Cloud Logging agent,
+// so SetOutput(os.Stdout) is structured logging to stdout,
+// as recommended for newer runtimes; see SetOutputFormat for alternatives.
+// A nil writer restores the logging client.
+func SetOutput(w io.Writer) {
+	outputMtx.Lock()
+	output = w
+	outputMtx.Unlock()
+}
+
+// SetMarshaler sets the function used to serialize entries as JSON,
+// when output is redirected with SetOutput.
+// A nil function restores json.Marshal.
+//
+// If marshaling fails, the message is written as plain text.
+func SetMarshaler(fn func(interface{}) ([]byte, error)) {
+	if fn == nil {
+		fn = json.Marshal
+	}
+	outputMtx.Lock()
+	marshal = fn
+	outputMtx.Unlock()
+}
+
+var vendor atomic.Value
+
+// SetVendorMapping adds the reserved fields a third party platform expects
+// to JSON output, alongside those of Google Cloud; see SetOutput.
+// The vendor is one of:
+//
+//	"":        no additional fields (the default)
+//	"datadog": status, dd.trace_id and dd.span_id
+//	"generic": level, trace_id and span_id
+//
+// Fields already in the payload (example: the status of LogTransport) aren't overwritten.
+func SetVendorMapping(v string) error {
+	switch v {
+	case "", "datadog", "generic":
+		vendor.Store(v)
+		return nil
+	}
+	return fmt.Errorf("logging: unknown vendor %q", v)
+}
+
+func hasOutput() bool {
+	outputMtx.Lock()
+	defer outputMtx.Unlock()
+	return output != nil
+}
+
+// writeOutput reports whether the entry was written to the output.
+func writeOutput(entry logging.Entry) bool {
+	outputMtx.Lock()
+	w, marshal := output, marshal
+	outputMtx.Unlock()
+	if w == nil {
+		return false
+	}
+
+	msg, fields := unpayload(entry.Payload)
+	var rec interface{}
+	if OutputFormat(atomic.LoadInt32(&outputFormat)) == BigQueryFormat {
+		rec = bigQueryRecord(entry, msg, fields)
+	} else {
+		rec = jsonEntry(entry, msg, fields)
+	}
+	buf, err := marshal(rec)
+	if err != nil {
+		buf = []byte(msg)
+	}
+	buf = append(buf, '\n')
+
+	outputMtx.Lock()
+	w.Write(buf)
+	outputMtx.Unlock()
+	return true
+}
+
+func jsonEntry(entry logging.Entry, msg string, fields map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(fields)+8)
+	for k, v := range fields {
+		m[k] = v
+	}
+	m[MessageKey()] = msg
+	m["severity"] = strings.ToUpper(entry.Severity.String())
+	if !entry.Timestamp.IsZero() {
+		m["time"] = entry.Timestamp
+	}
+	if len(entry.Labels) > 0 {
+		m["logging.googleapis.com/labels"] = entry.Labels
+	}
+	if entry.Trace != "" {
+		m["logging.googleapis.com/trace"] = entry.Trace
+	}
+	if entry.SpanID != "" {
+		m["logging.googleapis.com/spanId"] = entry.SpanID
+	}
+	if entry.TraceSampled {
+		m["logging.googleapis.com/trace_sampled"] = true
+	}
+	if entry.InsertID != "" {
+		m["logging.googleapis.com/insertId"] = entry.InsertID
+	}
+	if r := entry.HTTPRequest; r != nil {
+		m["httpRequest"] = jsonRequest(r)
+	}
+	if v, _ := vendor.Load().(string); v != "" {
+		vendorFields(v, m, entry)
+	}
+	if op := entry.Operation; op != nil {
+		m["logging.googleapis.com/operation"] = map[string]interface{}{
+			"id":       op.Id,
+			"producer": op.Producer,
+			"first":    op.First,
+			"last":     op.Last,
+		}
+	}
+	if loc := entry.SourceLocation; loc != nil {
+		m["logging.googleapis.com/sourceLocation"] = map[string]interface{}{
+			"file":     loc.File,
+			"line":     strconv.FormatInt(loc.Line, 10),
+			"function": loc.Function,
+		}
+	}
+	return m
+}
+
+func jsonRequest(r *logging.HTTPRequest) map[string]interface{} {
+	m := map[string]interface{}{}
+	if r.Request != nil {
+		m["requestMethod"] = r.Request.Method
+		m["requestUrl"] = r.Request.URL.String()
+		m["protocol"] = r.Request.Proto
+		if ua := r.Request.UserAgent(); ua != "" {
+			m["userAgent"] = ua
+		}
+		if ref := r.Request.Referer(); ref != "" {
+			m["referer"] = ref
+		}
+	}
+	if r.RequestSize > 0 {
+		m["requestSize"] = strconv.FormatInt(r.RequestSize, 10)
+	}
+	if r.Status != 0 {
+		m["status"] = r.Status
+	}
+	if r.ResponseSize > 0 {
+		m["responseSize"] = strconv.FormatInt(r.ResponseSize, 10)
+	}
+	if r.Latency > 0 {
+		m["latency"] = fmt.Sprintf("%.9fs", r.Latency.Seconds())
+	}
+	if r.RemoteIP != "" {
+		m["remoteIp"] = r.RemoteIP
+	}
+	if r.LocalIP != "" {
+		m["serverIp"] = r.LocalIP
+	}
+	if r.CacheHit {
+		m["cacheHit"] = true
+	}
+	return m
+}
+
+func vendorFields(vendor string, m map[string]interface{}, entry logging.Entry) {
+	trace := entry.Trace
+	if i := strings.LastIndexByte(trace, '/'); i >= 0 {
+		trace = trace[i+1:]
+	}
+	set := func(key string, value interface{}) {
+		if _, ok := m[key]; !ok {
+			m[key] = value
+		}
+	}
+
+	switch vendor {
+	case "datadog":
+		set("status", datadogStatus(entry.Severity))
+		// Datadog uses the lower 64 bits of trace IDs, in decimal.
+		if len(trace) > 16 {
+			trace = trace[len(trace)-16:]
+		}
+		if id, err := strconv.ParseUint(trace, 16, 64); err == nil {
+			set("dd.trace_id", strconv.FormatUint(id, 10))
+		}
+		if id, err := strconv.ParseUint(entry.SpanID, 16, 64); err == nil {
+			set("dd.span_id", strconv.FormatUint(id, 10))
+		}
+	case "generic":
+		set("level", SeverityString(entry.Severity))
+		if trace != "" {
+			set("trace_id", trace)
+		}
+		if entry.SpanID != "" {
+			set("span_id", entry.SpanID)
+		}
+	}
+}
+
+func datadogStatus(s logging.Severity) string {
+	switch {
+	case s >= logging.Emergency:
+		return "emergency"
+	case s >= logging.Alert:
+		return "alert"
+	case s >= logging.Critical:
+		return "critical"
+	case s >= logging.Error:
+		return "error"
+	case s >= logging.Warning:
+		return "warn"
+	case s >= logging.Notice:
+		return "notice"
+	case s >= logging.Info:
+		return "info"
+	case s >= logging.Debug:
+		return "debug"
+	}
+	return "info"
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestVendorMapping_collision(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+	defer SetVendorMapping("")
+
+	tests := []struct {
+		vendor, key string
+	}{
+		{"datadog", "status"},
+		{"generic", "level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.vendor, func(t *testing.T) {
+			if err := SetVendorMapping(tt.vendor); err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			Info(nil).WithFields(map[string]interface{}{tt.key: 503}).Print("collision")
+
+			var m map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if got := m[tt.key]; got != 503.0 {
+				t.Errorf("%s = %v, want 503", tt.key, got)
+			}
+
+			buf.Reset()
+			Info(nil).Print("no collision")
+			m = nil
+			if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if got := m[tt.key]; got != "info" {
+				t.Errorf("%s = %v, want info", tt.key, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+module github.com/ncruces/go-gcf/logging/pubsubsink
+
+go 1.25.0
+
+require (
+	cloud.google.com/go/logging v1.0.0
+	cloud.google.com/go/pubsub v1.4.0
+	github.com/ncruces/go-gcf v0.1.0
+	google.golang.org/api v0.28.0
+	google.golang.org/grpc v1.29.1
+)
+
+require (
+	cloud.google.com/go v0.60.0 // indirect
+	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
+	github.com/golang/protobuf v1.4.2 // indirect
+	github.com/google/go-cmp v0.5.0 // indirect
+	github.com/googleapis/gax-go/v2 v2.0.5 // indirect
+	github.com/jstemmer/go-junit-report v0.9.1 // indirect
+	go.opencensus.io v0.22.3 // indirect
+	golang.org/x/lint v0.0.0-20200302205851-738671d3881b // indirect
+	golang.org/x/mod v0.3.0 // indirect
+	golang.org/x/net v0.0.0-20200520182314-0ba52f642ac2 // indirect
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d // indirect
+	golang.org/x/sync v0.0.0-20200317015054-43a5402ce75a // indirect
+	golang.org/x/sys v0.0.0-20200523222454-059865788121 // indirect
+	golang.org/x/text v0.3.2 // indirect
+	golang.org/x/tools v0.0.0-20200626171337-aa94e735be7f // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
+	google.golang.org/appengine v1.6.6 // indirect
+	google.golang.org/genproto v0.0.0-20200707001353-8e8330bf89df // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
+
+// For local development; consumers get the required version.
+replace github.com/ncruces/go-gcf => ../../
@@ -0,0 +1,150 @@
+// Package pubsubsink publishes logged entries to a Pub/Sub topic,
+// for custom log pipelines.
+//
+// It's a separate module, to keep the Pub/Sub dependency
+// out of the logging package.
+//
+// Usage:
+//
+//	err := pubsubsink.PubSubSink(ctx, client.Topic("logs"))
+package pubsubsink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/pubsub"
+
+	gcf "github.com/ncruces/go-gcf/logging"
+)
+
+// PubSubSink publishes every entry logged to the topic, as JSON,
+// in addition to Cloud Logging. logging.Flush waits for outstanding publishes.
+//
+// It returns an error if the topic doesn't exist.
+func PubSubSink(ctx context.Context, topic *pubsub.Topic) error {
+	if topic == nil {
+		return errors.New("pubsubsink: nil topic")
+	}
+	ok, err := topic.Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("pubsubsink: topic " + topic.String() + " doesn't exist")
+	}
+
+	s := &sink{topic: topic}
+	gcf.AddHook(s.publish)
+	gcf.AddFlushHook(s.flush)
+	return nil
+}
+
+type sink struct {
+	topic   *pubsub.Topic
+	mtx     sync.Mutex
+	pending []*pubsub.PublishResult
+	prune   int   // prune pending at this length
+	failed  int   // publishes that failed since the last flush
+	err     error // first of those errors
+}
+
+// minPrune is the least number of pending results that triggers pruning.
+const minPrune = 64
+
+func (s *sink) publish(ctx context.Context, entry logging.Entry) {
+	buf, err := json.Marshal(message(entry))
+	if err != nil {
+		return
+	}
+	// Publish outlives the Context of the entry.
+	res := s.topic.Publish(context.Background(), &pubsub.Message{Data: buf})
+
+	s.mtx.Lock()
+	s.pending = append(s.pending, res)
+	if len(s.pending) >= s.prune {
+		s.pruneLocked()
+		s.prune = 2*len(s.pending) + minPrune
+	}
+	s.mtx.Unlock()
+}
+
+// pruneLocked drops results that are ready, keeping count of errors,
+// so that pending doesn't grow without bound between flushes.
+func (s *sink) pruneLocked() {
+	n := 0
+	for _, r := range s.pending {
+		select {
+		case <-r.Ready():
+			s.done(r)
+		default:
+			s.pending[n] = r
+			n++
+		}
+	}
+	for i := n; i < len(s.pending); i++ {
+		s.pending[i] = nil
+	}
+	s.pending = s.pending[:n]
+}
+
+// done records the outcome of a ready result.
+func (s *sink) done(r *pubsub.PublishResult) {
+	if _, err := r.Get(context.Background()); err != nil {
+		if s.failed == 0 {
+			s.err = err
+		}
+		s.failed++
+	}
+}
+
+func (s *sink) flush() error {
+	s.mtx.Lock()
+	pending := s.pending
+	s.pending, s.prune = nil, 0
+	s.mtx.Unlock()
+
+	for _, r := range pending {
+		<-r.Ready()
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, r := range pending {
+		s.done(r)
+	}
+	failed, err := s.failed, s.err
+	s.failed, s.err = 0, nil
+	if failed > 1 {
+		return fmt.Errorf("pubsubsink: %d publishes failed, first: %v", failed, err)
+	}
+	return err
+}
+
+func message(entry logging.Entry) map[string]interface{} {
+	m := map[string]interface{}{
+		"severity": entry.Severity.String(),
+		"payload":  entry.Payload,
+	}
+	if !entry.Timestamp.IsZero() {
+		m["timestamp"] = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	if len(entry.Labels) > 0 {
+		m["labels"] = entry.Labels
+	}
+	if entry.Trace != "" {
+		m["trace"] = entry.Trace
+	}
+	if entry.SpanID != "" {
+		m["spanId"] = entry.SpanID
+	}
+	if entry.InsertID != "" {
+		m["insertId"] = entry.InsertID
+	}
+	return m
+}
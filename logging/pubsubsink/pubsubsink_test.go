@@ -0,0 +1,57 @@
+package pubsubsink
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+func TestSink_prunesPending(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client, err := pubsub.NewClient(ctx, "project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	topic, err := client.CreateTopic(ctx, "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer topic.Stop()
+
+	s := &sink{topic: topic}
+	const entries = 1000
+	for i := 0; i < entries; i++ {
+		s.publish(ctx, logging.Entry{Payload: i})
+		if i%100 == 0 {
+			// Let outstanding publishes complete.
+			for _, r := range s.pending {
+				<-r.Ready()
+			}
+		}
+	}
+	if n := len(s.pending); n >= entries/2 {
+		t.Errorf("%d results pending, after %d publishes", n, entries)
+	}
+	if err := s.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Messages()); n != entries {
+		t.Errorf("%d messages published, want %d", n, entries)
+	}
+}
@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+// RecoverAndLog recovers from a panic, logging it at Critical, with a stack trace.
+// The raw stack trace is in the stack_trace field, recognized by Error Reporting,
+// and the parsed frames (function, file and line) are in the frames field.
+// The panic is swallowed. It must be called directly as a deferred function:
+//
+//	defer logging.RecoverAndLog(ctx)
+func RecoverAndLog(ctx context.Context) {
+	if v := recover(); v != nil {
+		logPanic(ctx, v)
+	}
+}
+
+// LogPanic is like RecoverAndLog, but continues panicking after logging.
+//
+//	defer logging.LogPanic(ctx)
+func LogPanic(ctx context.Context) {
+	if v := recover(); v != nil {
+		logPanic(ctx, v)
+		panic(v)
+	}
+}
+
+// RecoverError is like RecoverAndLog, but sets *errp to an error
+// describing the panic, for functions with a named error result.
+// It doesn't change *errp if there was no panic.
+//
+//	defer logging.RecoverError(ctx, &err)
+func RecoverError(ctx context.Context, errp *error) {
+	if v := recover(); v != nil {
+		logPanic(ctx, v)
+		if errp != nil {
+			*errp = panicError{v}
+		}
+	}
+}
+
+type panicError struct{ value interface{} }
+
+func (e panicError) Error() string {
+	return fmt.Sprint("panic: ", e.value)
+}
+
+// Unwrap returns the panic value, if it's an error.
+func (e panicError) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+// maxFrames caps the frames logged for a panic.
+const maxFrames = 64
+
+func logPanic(ctx context.Context, v interface{}) {
+	stack := debug.Stack()
+
+	var pcs [maxFrames + 1]uintptr
+	n := runtime.Callers(1, pcs[:])
+	truncated := n > maxFrames
+	if truncated {
+		n = maxFrames
+	}
+
+	var frames []map[string]interface{}
+	var caller *logpb.LogEntrySourceLocation
+	iter := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := iter.Next()
+		if f.Function == "runtime.gopanic" {
+			// Start at the panicking frame.
+			frames, caller = frames[:0], nil
+		} else {
+			if caller == nil && !strings.HasPrefix(f.Function, "runtime.") {
+				caller = frameLocation(f)
+			}
+			frames = append(frames, map[string]interface{}{
+				"function": f.Function,
+				"file":     f.File,
+				"line":     f.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	fields := map[string]interface{}{
+		"stack_trace": fmt.Sprintf("panic: %v\n\n%s", v, stack),
+		"frames":      frames,
+	}
+	if truncated {
+		fields["frames_truncated"] = true
+	}
+	l := Critical(ctx).withBuiltins(fields)
+	l.caller = caller
+	l.Printf("panic: %v", v)
+}
+
+// LogContextEnd logs that the Context is done, if it is,
+// at Warning if it was canceled, or at Error if its deadline was exceeded.
+// The entry includes the error, its cause (on Go 1.20 and above),
+// and the time elapsed since the start of the request; see Elapsed.
+//
+//	defer logging.LogContextEnd(ctx)
+func LogContextEnd(ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	err := ctx.Err()
+	if err == nil {
+		return
+	}
+
+	s := logging.Warning
+	if err == context.DeadlineExceeded {
+		s = logging.Error
+	}
+	newLogger(ctx, s).withBuiltins(map[string]interface{}{
+		"error":      err,
+		"cause":      cause(ctx),
+		"elapsed_ms": Elapsed(ctx).Seconds() * 1000,
+	}).Print("context done: ", err)
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"reflect"
+	"sync"
+)
+
+var (
+	schemaMtx sync.RWMutex
+	schema    map[string]reflect.Kind
+	strict    bool
+	warned    sync.Map
+)
+
+// RegisterSchema registers the expected kinds of structured fields,
+// after normalization (example: times are strings), see WithFields.
+//
+// Fields that don't conform are logged, with a one-time Warning,
+// or omitted in strict mode.
+func RegisterSchema(fields map[string]reflect.Kind) {
+	s := make(map[string]reflect.Kind, len(fields))
+	for k, v := range fields {
+		s[k] = v
+	}
+
+	schemaMtx.Lock()
+	schema = s
+	schemaMtx.Unlock()
+}
+
+// SetStrictSchema enables or disables strict mode,
+// where fields that don't conform to the schema,
+// or that are not in the schema, are omitted.
+//
+// Fields added by this package (example: stack_trace from RecoverAndLog,
+// or the fields of Logger.Audit) aren't subject to the schema.
+func SetStrictSchema(enabled bool) {
+	schemaMtx.Lock()
+	strict = enabled
+	schemaMtx.Unlock()
+}
+
+func applySchema(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	schemaMtx.RLock()
+	s, strict := schema, strict
+	schemaMtx.RUnlock()
+	if s == nil {
+		return fields
+	}
+
+	var res map[string]interface{}
+	for k, v := range fields {
+		kind, known := s[k]
+		ok := known && (v == nil || reflect.TypeOf(v).Kind() == kind)
+
+		if !ok && known {
+			if _, loaded := warned.LoadOrStore(k, struct{}{}); !loaded {
+				Warning(nil).Printf("logging: field %q does not conform to schema, expected %v, got %T", k, kind, v)
+			}
+		}
+		if !ok && strict {
+			if res == nil {
+				res = make(map[string]interface{}, len(fields))
+				for k, v := range fields {
+					res[k] = v
+				}
+			}
+			delete(res, k)
+		}
+	}
+	if res != nil {
+		return res
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestSchema_strict(t *testing.T) {
+	RegisterSchema(map[string]reflect.Kind{"user": reflect.String})
+	SetStrictSchema(true)
+	defer func() {
+		schemaMtx.Lock()
+		schema, strict = nil, false
+		schemaMtx.Unlock()
+	}()
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+
+	var got map[string]interface{}
+	defer addHook(func(_ context.Context, e logging.Entry) {
+		_, got = unpayload(e.Payload)
+	})()
+
+	// Unregistered fields named like those of this package are dropped.
+	Info(nil).WithFields(map[string]interface{}{
+		"user": "x", "status": 500, "code": "abc", "stack_trace": "fake",
+	}).Print("user fields")
+	if got["user"] != "x" || got["status"] != nil || got["code"] != nil || got["stack_trace"] != nil {
+		t.Errorf("user fields = %v", got)
+	}
+
+	// Fields added by this package are kept.
+	func() {
+		defer RecoverAndLog(nil)
+		panic("boom")
+	}()
+	if got["stack_trace"] == nil || got["frames"] == nil {
+		t.Errorf("panic fields = %v", got)
+	}
+
+	err := Info(nil).Audit(AuditEvent{Principal: "p", MethodName: "m", ResourceName: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"@type", "authenticationInfo", "methodName", "resourceName"} {
+		if got[k] == nil {
+			t.Errorf("audit fields = %v, missing %q", got, k)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"cloud.google.com/go/logging"
+)
+
+// SeverityString returns the canonical lowercase name of the severity
+// (example: "warning"). It's the inverse of ParseSeverity.
+func SeverityString(s logging.Severity) string {
+	return strings.ToLower(s.String())
+}
+
+// ParseSeverity parses a severity name, ignoring case.
+// Unlike logging.ParseSeverity, it fails for unknown names.
+func ParseSeverity(name string) (logging.Severity, error) {
+	s := logging.ParseSeverity(name)
+	if s == logging.Default && !strings.EqualFold(name, "default") {
+		return s, fmt.Errorf("logging: unknown severity %q", name)
+	}
+	return s, nil
+}
+
+var defaultSeverity = int32(envSeverity("DEFAULT_SEVERITY"))
+
+// SetDefaultSeverity sets the severity of entries logged by Default
+// (default: logging.Default, or the DEFAULT_SEVERITY environment variable).
+func SetDefaultSeverity(s logging.Severity) {
+	atomic.StoreInt32(&defaultSeverity, int32(s))
+}
+
+func envSeverity(name string) logging.Severity {
+	v := os.Getenv(name)
+	if v == "" {
+		return logging.Default
+	}
+	s, err := ParseSeverity(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid %s environment variable: %v\n", name, err)
+	}
+	return s
+}
+
+// A Level is a severity that marshals to and from its canonical name,
+// for use in configuration files.
+type Level logging.Severity
+
+// String implements fmt.Stringer.
+func (l Level) String() string {
+	return SeverityString(logging.Severity(l))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	s, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*l = Level(s)
+	return nil
+}
+
+var levelLabel atomic.Value
+
+// SetCustomLevelLabel sets the label that holds the name of custom levels
+// (default: "level"); see Custom.
+func SetCustomLevelLabel(key string) {
+	if key == "" {
+		key = "level"
+	}
+	levelLabel.Store(key)
+}
+
+// Custom gets a Logger for a custom level (example: "AUDIT").
+// Entries are logged at the base severity, for filtering,
+// and labeled with the level name; see SetCustomLevelLabel.
+func Custom(ctx context.Context, base logging.Severity, name string) Logger {
+	key, ok := levelLabel.Load().(string)
+	if !ok {
+		key = "level"
+	}
+	return newLogger(ctx, base).WithLabels(map[string]string{key: name})
+}
@@ -0,0 +1,126 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/logging"
+)
+
+// NewSlogHandler creates a slog.Handler that logs through this package,
+// at or above the minimum level (nil means slog.LevelInfo).
+//
+// Entries are correlated with the Context passed to the slog.Logger,
+// including its trace; see ForRequest.
+// Attributes become structured fields, except those with keys
+// reserved by Cloud Logging (example: "logging.googleapis.com/trace"),
+// which set the corresponding entry fields, in both the API and SetOutput paths.
+// With SetReportCaller, the source location is that of the slog call.
+func NewSlogHandler(min slog.Leveler) slog.Handler {
+	if min == nil {
+		min = slog.LevelInfo
+	}
+	return &slogHandler{min: min}
+}
+
+type slogHandler struct {
+	min    slog.Leveler
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.min.Level()
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := map[string]interface{}{}
+	var reserved []slog.Attr
+	add := func(prefix string, a slog.Attr) {
+		if isReserved(a.Key) {
+			reserved = append(reserved, a)
+		} else {
+			addAttr(fields, prefix, a)
+		}
+	}
+	for _, a := range h.attrs {
+		add("", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		add(h.prefix, a)
+		return true
+	})
+
+	l := newLogger(ctx, slogSeverity(r.Level))
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+	// The first frame outside this package is in log/slog.
+	if r.PC != 0 && reportingCaller() {
+		l.caller = pcLocation(r.PC)
+	}
+	l.logWith(r.Message, func(e *logging.Entry) {
+		if !r.Time.IsZero() {
+			e.Timestamp = r.Time
+		}
+		for _, a := range reserved {
+			setReserved(e, a.Key, a.Value.String())
+		}
+	})
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	c.attrs = append(c.attrs, h.attrs...)
+	for _, a := range attrs {
+		if h.prefix != "" && !isReserved(a.Key) {
+			a.Key = h.prefix + a.Key
+		}
+		c.attrs = append(c.attrs, a)
+	}
+	return &c
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+func addAttr(fields map[string]interface{}, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, a := range v.Group() {
+			addAttr(fields, prefix, a)
+		}
+		return
+	}
+	if a.Key != "" {
+		fields[prefix+a.Key] = v.Any()
+	}
+}
+
+func slogSeverity(level slog.Level) logging.Severity {
+	switch {
+	case level > slog.LevelError:
+		return logging.Critical
+	case level >= slog.LevelError:
+		return logging.Error
+	case level >= slog.LevelWarn:
+		return logging.Warning
+	case level >= slog.LevelInfo:
+		return logging.Info
+	}
+	return logging.Debug
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestSlogHandler_sourceLocation(t *testing.T) {
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+	SetReportCaller(true)
+	defer SetReportCaller(false)
+
+	var got logging.Entry
+	defer addHook(func(_ context.Context, e logging.Entry) { got = e })()
+
+	slog.New(NewSlogHandler(nil)).Info("test")
+
+	loc := got.SourceLocation
+	if loc == nil {
+		t.Fatal("no source location")
+	}
+	if !strings.HasSuffix(loc.File, "slog_test.go") || !strings.HasSuffix(loc.Function, ".TestSlogHandler_sourceLocation") {
+		t.Errorf("source location = %s:%d %s", loc.File, loc.Line, loc.Function)
+	}
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+)
+
+type spanKey struct{}
+
+// Span starts a named operation, logging its start and end,
+// at the Logger's severity (example: logging.Debug(ctx).Span(ctx, "load")).
+//
+// Nested spans are linked to their parent: start and end entries
+// have span and parent_span fields, and end entries have a duration_ms field.
+// Use the returned Context to start nested spans,
+// and call the returned function to end the span.
+func (l Logger) Span(ctx context.Context, name string) (context.Context, func()) {
+	if l.noop {
+		return ctx, func() {}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent, _ := ctx.Value(spanKey{}).(string)
+	id := newSpanID()
+	ctx = context.WithValue(ctx, spanKey{}, id)
+
+	fields := map[string]interface{}{"span": id}
+	if parent != "" {
+		fields["parent_span"] = parent
+	}
+	l = l.withBuiltins(fields)
+	l.Print("start: ", name)
+
+	start := now()
+	return ctx, func() {
+		l.withBuiltins(map[string]interface{}{
+			"duration_ms": now().Sub(start).Seconds() * 1000,
+		}).Print("end: ", name)
+	}
+}
+
+func newSpanID() string {
+	var id [8]byte
+	crand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// WithSpanLinks gets a Logger that links entries to other spans
+// (example: those of parallel downstream calls), as the span_links field.
+// Links accumulate over nested calls, and don't replace the entry's own trace and span.
+//
+// Cloud Logging has no metadata for span links, so they're only a field.
+func (l Logger) WithSpanLinks(spanIDs ...string) Logger {
+	if l.noop || len(spanIDs) == 0 {
+		return l
+	}
+	var links []string
+	if prev, ok := l.builtins["span_links"].([]string); ok {
+		links = append(links, prev...)
+	}
+	links = append(links, spanIDs...)
+	return l.withBuiltins(map[string]interface{}{"span_links": links})
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type buildInfo struct {
+	goVersion string
+	revision  string
+	time      string
+}
+
+var (
+	buildOnce     sync.Once
+	build         buildInfo
+	revisionLabel int32
+)
+
+// getBuildInfo reads build info once.
+// VCS info is unavailable before Go 1.18, or without VCS stamping (example: go run).
+func getBuildInfo() buildInfo {
+	buildOnce.Do(func() {
+		build = readBuildInfo()
+		if build.goVersion == "" {
+			build.goVersion = runtime.Version()
+		}
+	})
+	return build
+}
+
+// SetRevisionLabel enables or disables the "revision" label:
+// the VCS revision the binary was built from, if available.
+func SetRevisionLabel(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&revisionLabel, v)
+}
+
+// LogStartup logs a startup summary at Notice,
+// with the Go version, the VCS revision and time, if available,
+// and the initialization duration, as init_duration_ms.
+//
+// The initialization duration is the time from process start to the first request,
+// or the first entry, whichever comes first. It's also added to the first entry.
+func LogStartup(ctx context.Context) {
+	info := getBuildInfo()
+	fields := map[string]interface{}{
+		"go_version":       info.goVersion,
+		"init_duration_ms": markInit().Seconds() * 1000,
+	}
+	if info.revision != "" {
+		fields["vcs_revision"] = info.revision
+	}
+	if info.time != "" {
+		fields["vcs_time"] = info.time
+	}
+	Notice(ctx).withBuiltins(fields).Print("startup")
+}
+
+var (
+	initOnce     sync.Once
+	initDuration time.Duration
+	initPending  int32 = 1
+)
+
+// markInit records the initialization duration:
+// the time from process start (approximately, when this package is initialized)
+// to the first request, or the first entry, whichever comes first.
+func markInit() time.Duration {
+	initOnce.Do(func() { initDuration = time.Since(processStart) })
+	return initDuration
+}
+
+// initFields adds the init_duration_ms field to the first entry logged.
+func initFields(fields map[string]interface{}) map[string]interface{} {
+	if atomic.LoadInt32(&initPending) == 0 || !atomic.CompareAndSwapInt32(&initPending, 1, 0) {
+		return fields
+	}
+	return addFields(fields, map[string]interface{}{
+		"init_duration_ms": markInit().Seconds() * 1000,
+	})
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"cloud.google.com/go/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Status logs the error with a severity derived from its gRPC status code,
+// and the code as the grpc_code label, overriding the Logger's severity.
+// For example, NotFound is logged at Info, Unavailable at Warning,
+// and Internal at Error. Errors without a status are logged at Error.
+// Like WrapErr, it does nothing for a nil error.
+func (l Logger) Status(err error) {
+	if err == nil || l.noop {
+		return
+	}
+
+	code := status.Code(err)
+	l.s = codeSeverity(code)
+	l.WithLabels(map[string]string{"grpc_code": code.String()}).log(err.Error())
+}
+
+func codeSeverity(c codes.Code) logging.Severity {
+	switch c {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return logging.Info
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return logging.Warning
+	}
+	return logging.Error
+}
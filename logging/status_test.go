@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	SetFallback(FallbackDiscard)
+	defer SetFallback(FallbackStdout)
+
+	var got []logging.Entry
+	defer addHook(func(_ context.Context, e logging.Entry) { got = append(got, e) })()
+
+	Error(nil).Status(nil)
+	if len(got) != 0 {
+		t.Fatalf("nil error logged %d entries", len(got))
+	}
+
+	Error(nil).Status(status.Error(codes.NotFound, "missing"))
+	if len(got) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(got))
+	}
+	if e := got[0]; e.Severity != logging.Info || e.Labels["grpc_code"] != "NotFound" {
+		t.Errorf("entry at %v, with grpc_code %q", e.Severity, e.Labels["grpc_code"])
+	}
+}
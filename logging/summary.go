@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+type summaryKey struct{}
+
+// A RequestSummary accumulates fields during a request,
+// to be logged as a single summary entry when the request ends.
+//
+// The Handler logs the summary as its access entry.
+// Without the Handler, call Flush at the end of the request.
+type RequestSummary struct {
+	ctx context.Context
+	req *http.Request
+
+	mtx    sync.Mutex
+	fields map[string]interface{}
+	errs   []string
+	done   bool
+	labels map[string]string
+	max    int32
+}
+
+// Summary gets the RequestSummary of a Context created by ForRequest.
+// It returns nil otherwise; methods on a nil RequestSummary do nothing.
+func Summary(ctx context.Context) *RequestSummary {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(summaryKey{}).(*RequestSummary)
+	return s
+}
+
+func withSummary(ctx context.Context, r *http.Request) context.Context {
+	s := &RequestSummary{req: r}
+	ctx = context.WithValue(ctx, summaryKey{}, s)
+	s.ctx = ctx
+	return ctx
+}
+
+// Set sets a summary field (example: summary.Set("db_queries", 4)).
+func (s *RequestSummary) Set(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	if s.fields == nil {
+		s.fields = map[string]interface{}{}
+	}
+	s.fields[key] = value
+	s.mtx.Unlock()
+}
+
+// Error records a non-nil error.
+// The summary is logged at Error or above if any errors were recorded.
+func (s *RequestSummary) Error(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mtx.Lock()
+	s.errs = append(s.errs, err.Error())
+	s.mtx.Unlock()
+}
+
+// AddError records a non-nil error in the request's Summary,
+// to be reported, with all other errors, at the end of the request.
+//
+// If the Context has no Summary, the error is logged immediately, at Error.
+func AddError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if s := Summary(ctx); s != nil {
+		s.Error(err)
+	} else {
+		Error(ctx).Print(err)
+	}
+}
+
+// RecordLatency adds the duration to a latency breakdown field
+// of the request's Summary, in milliseconds (example: "db_ms").
+// Durations recorded with the same name are added up.
+// If the Context has no Summary, it does nothing.
+func RecordLatency(ctx context.Context, name string, d time.Duration) {
+	s := Summary(ctx)
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	if s.fields == nil {
+		s.fields = map[string]interface{}{}
+	}
+	ms, _ := s.fields[name].(float64)
+	s.fields[name] = ms + d.Seconds()*1000
+	s.mtx.Unlock()
+}
+
+// MaxSeverity gets the highest severity of entries logged so far
+// with a Context created by ForRequest, or Default otherwise.
+// The request's Summary is logged with at least this severity.
+func MaxSeverity(ctx context.Context) logging.Severity {
+	if s := Summary(ctx); s != nil {
+		return logging.Severity(atomic.LoadInt32(&s.max))
+	}
+	return logging.Default
+}
+
+// Flush logs the summary, unless it was already logged.
+func (s *RequestSummary) Flush() {
+	if s == nil {
+		return
+	}
+	req := httpRequest(s.req)
+	req.Latency = Elapsed(s.ctx)
+	s.emit(logging.Info, req, nil)
+}
+
+// emit logs the summary, with the caller's source location, if not nil.
+func (s *RequestSummary) emit(sev logging.Severity, req *logging.HTTPRequest, caller *logpb.LogEntrySourceLocation) {
+	s.mtx.Lock()
+	if s.done {
+		s.mtx.Unlock()
+		return
+	}
+	s.done = true
+	fields := make(map[string]interface{}, len(s.fields))
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+	var builtins map[string]interface{}
+	if len(s.errs) > 0 {
+		builtins = map[string]interface{}{"errors": s.errs}
+		if sev < logging.Error {
+			sev = logging.Error
+		}
+	}
+	if max := logging.Severity(atomic.LoadInt32(&s.max)); sev < max {
+		sev = max
+	}
+	labels := s.labels
+	s.mtx.Unlock()
+
+	l := newLogger(s.ctx, sev).WithFields(fields).withBuiltins(builtins).WithLabels(labels)
+	l.req = req
+	l.caller = caller
+	if req.Status != 0 {
+		l.Printf("%s %s %d", s.req.Method, s.req.URL.RequestURI(), req.Status)
+	} else {
+		l.Printf("%s %s", s.req.Method, s.req.URL.RequestURI())
+	}
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"sync"
+
+	"cloud.google.com/go/logging"
+)
+
+type tee struct {
+	match  func(logging.Entry) bool
+	target *NamedLogger
+}
+
+var (
+	teesMtx sync.RWMutex
+	tees    []tee
+)
+
+// AddTee registers a secondary sink: entries for which predicate
+// returns true are also logged to target, in addition to their own log.
+//
+// Multiple tees can be registered; predicates are called synchronously,
+// after filtering, and must not modify entries.
+//
+// Tees only apply to entries sent to Cloud Logging: local output,
+// including that of SetOutput, has a single stream, so entries would be written twice.
+func AddTee(predicate func(logging.Entry) bool, target *NamedLogger) {
+	teesMtx.Lock()
+	tees = append(tees, tee{predicate, target})
+	teesMtx.Unlock()
+}
+
+func runTees(nl *NamedLogger, entry logging.Entry) {
+	teesMtx.RLock()
+	ts := tees
+	teesMtx.RUnlock()
+	if len(ts) == 0 || hasOutput() || !hasClient() {
+		return
+	}
+
+	for _, t := range ts {
+		if t.target != nil && t.target != nl && t.match(entry) {
+			if !enqueue(t.target, entry) {
+				emit(t.target, entry)
+			}
+		}
+	}
+}
+
+func hasClient() bool {
+	setup()
+	clientMtx.RLock()
+	defer clientMtx.RUnlock()
+	return client != nil
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+func withTee(t *testing.T, target *NamedLogger) func() {
+	teesMtx.Lock()
+	old := tees
+	teesMtx.Unlock()
+	AddTee(func(logging.Entry) bool { return true }, target)
+	return func() {
+		teesMtx.Lock()
+		tees = old
+		teesMtx.Unlock()
+	}
+}
+
+func TestTee_output(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+	defer withTee(t, NewNamedLogger("tee"))()
+
+	Info(nil).Print("teed")
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 1 {
+		t.Errorf("wrote %d lines, want 1:\n%s", n, buf.Bytes())
+	}
+}
+
+func TestTee_client(t *testing.T) {
+	fake, stop := startFakeLogging(t)
+	defer stop()
+	defer withTee(t, NewNamedLogger("tee"))()
+
+	Info(nil).Print("teed")
+	if err := Flush(); err != nil {
+		t.Fatal(err)
+	}
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if n := fake.messages["teed"]; n != 2 {
+		t.Errorf("logged %d times, want 2", n)
+	}
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxThrottleKeys bounds the keys tracked by Throttle.
+const maxThrottleKeys = 1024
+
+type throttled struct {
+	last       time.Time
+	every      time.Duration
+	suppressed int
+}
+
+var (
+	throttleMtx  sync.Mutex
+	throttleKeys = map[string]*throttled{}
+)
+
+// Throttle logs using the default formats for its operands,
+// at most once every interval for each key.
+// The next entry logged for a key includes the count of those suppressed,
+// as the suppressed field.
+//
+// At most 1024 keys are tracked; if more are active, entries for new keys
+// are logged without throttling.
+func (l Logger) Throttle(key string, every time.Duration, v ...interface{}) {
+	if l.noop {
+		return
+	}
+
+	t := now()
+	throttleMtx.Lock()
+	s := throttleKeys[key]
+	if s != nil && t.Sub(s.last) < every {
+		s.suppressed++
+		throttleMtx.Unlock()
+		return
+	}
+	var suppressed int
+	if s != nil {
+		suppressed = s.suppressed
+		s.last, s.every, s.suppressed = t, every, 0
+	} else if len(throttleKeys) < maxThrottleKeys || evictThrottled(t) {
+		throttleKeys[key] = &throttled{last: t, every: every}
+	}
+	throttleMtx.Unlock()
+
+	if suppressed > 0 {
+		l = l.withBuiltins(map[string]interface{}{"suppressed": suppressed})
+	}
+	l.log(fmt.Sprint(v...))
+}
+
+// evictThrottled removes keys past their interval, which have no suppressed entries,
+// and reports if there's room for a new key.
+// It must be called with throttleMtx held.
+func evictThrottled(t time.Time) bool {
+	for k, s := range throttleKeys {
+		if s.suppressed == 0 && t.Sub(s.last) >= s.every {
+			delete(throttleKeys, k)
+		}
+	}
+	return len(throttleKeys) < maxThrottleKeys
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+// A TransportOption configures LogTransport.
+type TransportOption func(*transport)
+
+// WithHeaders includes request and response headers in entries.
+func WithHeaders() TransportOption {
+	return func(t *transport) { t.headers = true }
+}
+
+// WithRedactedHeaders redacts the values of the headers (example: "Authorization").
+func WithRedactedHeaders(names ...string) TransportOption {
+	return func(t *transport) {
+		for _, n := range names {
+			t.redact[http.CanonicalHeaderKey(n)] = struct{}{}
+		}
+	}
+}
+
+// LogTransport wraps an http.RoundTripper (nil means http.DefaultTransport),
+// logging each outbound request at Debug, with its method, URL, status and latency.
+// Entries are correlated with the Context, or with the request's Context if nil.
+//
+// With SetReportCaller, the source location of entries is where LogTransport was called.
+//
+// Request and response bodies are neither consumed nor altered.
+func LogTransport(ctx context.Context, rt http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t := &transport{ctx: ctx, next: rt, redact: map[string]struct{}{}, caller: callerLocation(0)}
+	for _, o := range opts {
+		o(t)
+	}
+	return t
+}
+
+type transport struct {
+	ctx     context.Context
+	next    http.RoundTripper
+	headers bool
+	redact  map[string]struct{}
+	caller  *logpb.LogEntrySourceLocation
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := now()
+	res, err := t.next.RoundTrip(req)
+	latency := now().Sub(start)
+
+	ctx := t.ctx
+	if ctx == nil {
+		ctx = req.Context()
+	}
+
+	url := *req.URL
+	url.User = nil
+
+	fields := map[string]interface{}{
+		"method":     req.Method,
+		"url":        url.String(),
+		"latency_ms": latency.Seconds() * 1000,
+	}
+	if t.headers {
+		fields["request_headers"] = t.header(req.Header)
+	}
+
+	status := 0
+	if err != nil {
+		fields["error"] = err
+	} else {
+		status = res.StatusCode
+		fields["status"] = status
+		if t.headers {
+			fields["response_headers"] = t.header(res.Header)
+		}
+	}
+
+	l := newLogger(ctx, logging.Debug).withBuiltins(fields)
+	l.caller = t.caller
+	l.Printf("%s %s %d", req.Method, url.String(), status)
+	return res, err
+}
+
+func (t *transport) header(h http.Header) map[string]string {
+	res := make(map[string]string, len(h))
+	for k, v := range h {
+		if _, ok := t.redact[k]; ok {
+			res[k] = "REDACTED"
+		} else {
+			res[k] = strings.Join(v, ", ")
+		}
+	}
+	return res
+}
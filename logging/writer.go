@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/logging"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+)
+
+// A WriterOption configures a Writer.
+type WriterOption func(*writer)
+
+// WithLevelPrefixes parses a leading severity token, followed by a colon,
+// from each line (example: "ERROR: something failed"),
+// logging the rest of the line with that severity.
+// WARN is accepted for Warning. Tokens must be uppercase.
+// Lines without a recognized token use the Logger's severity.
+func WithLevelPrefixes() WriterOption {
+	return func(w *writer) { w.prefixes = true }
+}
+
+// Writer gets an io.Writer that logs each line written to it with the Logger,
+// to route legacy output, like that of a log.Logger:
+//
+//	log.New(logging.Info(ctx).Writer(), "", 0)
+//
+// Incomplete lines are buffered until a newline is written.
+// With SetReportCaller, the source location is the caller of the log, fmt or io functions
+// that wrote the newline.
+func (l Logger) Writer(opts ...WriterOption) io.Writer {
+	w := &writer{l: l}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+type writer struct {
+	l        Logger
+	prefixes bool
+
+	mtx sync.Mutex
+	buf []byte
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	// Report the caller of log or fmt, rather than their frames.
+	var caller *logpb.LogEntrySourceLocation
+	if reportingCaller() {
+		caller = callerLocation(w.l.skip, "log.", "fmt.", "io.", "bufio.")
+	}
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(string(w.buf[:i]), caller)
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *writer) line(s string, caller *logpb.LogEntrySourceLocation) {
+	l := w.l
+	l.caller = caller
+	if w.prefixes {
+		if i := strings.IndexByte(s, ':'); i > 0 && s[:i] == strings.ToUpper(s[:i]) {
+			if sev, ok := prefixSeverity(s[:i]); ok {
+				l.s = sev
+				s = strings.TrimLeft(s[i+1:], " ")
+			}
+		}
+	}
+	l.log(s)
+}
+
+func prefixSeverity(token string) (logging.Severity, bool) {
+	if token == "WARN" {
+		return logging.Warning, true
+	}
+	if s, err := ParseSeverity(token); err == nil {
+		return s, true
+	}
+	return logging.Default, false
+}